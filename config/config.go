@@ -5,6 +5,8 @@ import (
 	"crypto/rsa"
 	"encoding/json"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 	log "github.com/sirupsen/logrus"
@@ -38,6 +40,34 @@ type Config struct {
 	// Z.AI
 	ZaiAPIKey string
 	ZaiModel  string
+
+	// OpenAI-compatible server, e.g. a local Ollama or llama.cpp
+	OpenAICompatibleBaseURL string
+	OpenAICompatibleAPIKey  string
+	OpenAICompatibleModel   string
+
+	// LLM provider selection: openrouter, zai, openai_compatible or fake
+	LLMProvider         string
+	LLMFallbackProvider string
+	LLMTimeout          time.Duration
+	LLMContextTokens    int // Context window of the configured models, larger prompts are summarized in chunks
+
+	// Cache
+	CacheEnabled    bool
+	CacheWorkLogTTL time.Duration
+	CacheSummaryTTL time.Duration
+
+	// Background jobs
+	JobWorkers int
+	JobTimeout time.Duration // Running jobs older than this are considered abandoned by a dead worker
+
+	// Scheduler
+	SchedulerEnabled bool
+	AutoSummaryCron  string
+
+	// Trash
+	TrashRetentionDays int
+	TrashPurgeCron     string
 }
 
 // GoogleOAuthJSON represents the structure of Google OAuth credentials JSON
@@ -63,14 +93,30 @@ func Initialize() {
 	}
 
 	cfg = &Config{
-		DatabaseURL:      getEnvOrDefault("DATABASE_URL", "postgres://localhost:5432/worknote?sslmode=disable"),
-		RedisURL:         getEnvOrDefault("REDIS_URL", "localhost:6379"),
-		RedisPassword:    os.Getenv("REDIS_PASSWORD"),
-		Port:             getEnvOrDefault("PORT", "8080"),
-		OpenRouterAPIKey: os.Getenv("OPENROUTER_API_KEY"),
-		OpenRouterModel:  getEnvOrDefault("OPENROUTER_MODEL", "openai/gpt-4o-mini"),
-		ZaiAPIKey:        os.Getenv("ZAI_API_KEY"),
-		ZaiModel:         getEnvOrDefault("ZAI_MODEL", "glm-4.7"),
+		DatabaseURL:             getEnvOrDefault("DATABASE_URL", "postgres://localhost:5432/worknote?sslmode=disable"),
+		RedisURL:                getEnvOrDefault("REDIS_URL", "localhost:6379"),
+		RedisPassword:           os.Getenv("REDIS_PASSWORD"),
+		Port:                    getEnvOrDefault("PORT", "8080"),
+		OpenRouterAPIKey:        os.Getenv("OPENROUTER_API_KEY"),
+		OpenRouterModel:         getEnvOrDefault("OPENROUTER_MODEL", "openai/gpt-4o-mini"),
+		ZaiAPIKey:               os.Getenv("ZAI_API_KEY"),
+		ZaiModel:                getEnvOrDefault("ZAI_MODEL", "glm-4.7"),
+		OpenAICompatibleBaseURL: os.Getenv("OPENAI_COMPATIBLE_BASE_URL"),
+		OpenAICompatibleAPIKey:  os.Getenv("OPENAI_COMPATIBLE_API_KEY"),
+		OpenAICompatibleModel:   getEnvOrDefault("OPENAI_COMPATIBLE_MODEL", "llama3.1"),
+		LLMProvider:             getEnvOrDefault("LLM_PROVIDER", "openrouter"),
+		LLMFallbackProvider:     os.Getenv("LLM_FALLBACK_PROVIDER"),
+		LLMTimeout:              getDurationOrDefault("LLM_TIMEOUT", 2*time.Minute),
+		LLMContextTokens:        getIntOrDefault("LLM_CONTEXT_TOKENS", 32000),
+		CacheEnabled:            getBoolOrDefault("CACHE_ENABLED", true),
+		CacheWorkLogTTL:         getDurationOrDefault("CACHE_WORK_LOG_TTL", 10*time.Minute),
+		CacheSummaryTTL:         getDurationOrDefault("CACHE_SUMMARY_TTL", time.Hour),
+		JobWorkers:              getIntOrDefault("JOB_WORKERS", 2),
+		JobTimeout:              getDurationOrDefault("JOB_TIMEOUT", 15*time.Minute),
+		SchedulerEnabled:        getBoolOrDefault("SCHEDULER_ENABLED", true),
+		AutoSummaryCron:         getEnvOrDefault("AUTO_SUMMARY_CRON", "0 2 1 * *"),
+		TrashRetentionDays:      getIntOrDefault("TRASH_RETENTION_DAYS", 30),
+		TrashPurgeCron:          getEnvOrDefault("TRASH_PURGE_CRON", "30 3 * * *"),
 	}
 
 	// Parse Google OAuth JSON
@@ -142,3 +188,42 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getBoolOrDefault(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Warnf("invalid boolean for %s, using default: %v", key, err)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getIntOrDefault(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Warnf("invalid integer for %s, using default: %v", key, err)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Warnf("invalid duration for %s, using default: %v", key, err)
+		return defaultValue
+	}
+	return parsed
+}
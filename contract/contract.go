@@ -1,6 +1,8 @@
 package contract
 
 import (
+	"encoding/json"
+
 	"worknote-api/model"
 
 	"github.com/go-jose/go-jose/v3/jwt"
@@ -111,7 +113,7 @@ type JobApplicationLogListResponse struct {
 
 // UpsertWorkLogRequest is the request body for upserting a work log
 type UpsertWorkLogRequest struct {
-	Date    string `json:"date"`
+	Date    string `json:"date"` // YYYY-MM-DD, or today, yesterday, -N in the user's timezone
 	Content string `json:"content"`
 	Append  bool   `json:"append,omitempty"`
 }
@@ -125,21 +127,691 @@ type WorkLogResponse struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
+// WorkLogItemResponse is the response for one bullet of a work log
+type WorkLogItemResponse struct {
+	ID              int64  `json:"id"`
+	Position        int    `json:"position"`
+	Text            string `json:"text"`
+	Status          string `json:"status"`
+	ProjectID       int64  `json:"project_id,omitempty"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+	StartedAt       string `json:"started_at,omitempty"`
+	EndedAt         string `json:"ended_at,omitempty"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+// WorkLogItemListResponse is the response for listing the bullets of a work log
+type WorkLogItemListResponse struct {
+	Data    []WorkLogItemResponse `json:"data"`
+	Content string                `json:"content"` // The rendered work log content
+}
+
+// CreateWorkLogItemRequest is the request body for adding a bullet to a work log
+type CreateWorkLogItemRequest struct {
+	Text            string `json:"text"`
+	Status          string `json:"status,omitempty"` // done (default), in-progress or blocked
+	ProjectID       int64  `json:"project_id,omitempty"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"` // Derived from started_at and ended_at when omitted
+	StartedAt       string `json:"started_at,omitempty"`       // RFC3339, given together with ended_at
+	EndedAt         string `json:"ended_at,omitempty"`
+	Position        *int   `json:"position,omitempty"` // Zero-based, defaults to the end
+}
+
+// UpdateWorkLogItemRequest is the request body for editing a bullet.
+// A zero project_id or duration_minutes and empty started_at/ended_at clear them.
+type UpdateWorkLogItemRequest struct {
+	Text            *string `json:"text,omitempty"`
+	Status          *string `json:"status,omitempty"`
+	ProjectID       *int64  `json:"project_id,omitempty"`
+	DurationMinutes *int    `json:"duration_minutes,omitempty"`
+	StartedAt       *string `json:"started_at,omitempty"`
+	EndedAt         *string `json:"ended_at,omitempty"`
+}
+
+// ReorderWorkLogItemsRequest is the request body for reordering the bullets of a work log
+type ReorderWorkLogItemsRequest struct {
+	ItemIDs []int64 `json:"item_ids"` // Every item of the day, in the new order
+}
+
+// ListWorkLogsRequest holds the query parameters for listing work logs
+type ListWorkLogsRequest struct {
+	From   string `query:"from"` // Format: YYYY-MM-DD, inclusive
+	To     string `query:"to"`   // Format: YYYY-MM-DD, inclusive
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"` // next_cursor of the previous page
+	Order  string `query:"order"`  // asc or desc (default)
+	Tag    string `query:"tag"`    // #tag, tag or @mention
+}
+
 // WorkLogListResponse is the response for listing work logs
 type WorkLogListResponse struct {
-	Data []WorkLogResponse `json:"data"`
+	Data       []WorkLogResponse `json:"data"`
+	Total      int               `json:"total"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// SearchWorkLogsRequest holds the query parameters for searching work logs
+type SearchWorkLogsRequest struct {
+	Query  string `query:"q"`
+	From   string `query:"from"` // Format: YYYY-MM-DD, inclusive
+	To     string `query:"to"`   // Format: YYYY-MM-DD, inclusive
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+// WorkLogSearchResultResponse is a single full-text search hit
+type WorkLogSearchResultResponse struct {
+	ID      int64   `json:"id"`
+	Date    string  `json:"date"`
+	Snippet string  `json:"snippet"` // HTML-escaped text with the matches wrapped in <mark>
+	Rank    float64 `json:"rank"`
+}
+
+// WorkLogSearchResponse is the response for searching work logs
+type WorkLogSearchResponse struct {
+	Data  []WorkLogSearchResultResponse `json:"data"`
+	Total int                           `json:"total"`
+}
+
+// ListWorkLogTagsRequest holds the query parameters for listing tag counts
+type ListWorkLogTagsRequest struct {
+	Kind  string `query:"kind"` // tag or mention, both when empty
+	From  string `query:"from"`
+	To    string `query:"to"`
+	Limit int    `query:"limit"`
+}
+
+// WorkLogTagCountResponse is the usage count of a tag or mention
+type WorkLogTagCountResponse struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Days        int    `json:"days"`
+	Occurrences int    `json:"occurrences"`
+	LastUsedOn  string `json:"last_used_on"`
+}
+
+// WorkLogTagListResponse is the response for listing tag counts
+type WorkLogTagListResponse struct {
+	Data []WorkLogTagCountResponse `json:"data"`
 }
 
 // GenerateSummaryRequest is the request body for generating a monthly summary
 type GenerateSummaryRequest struct {
-	Month string `json:"month"` // Format: YYYY-MM
+	Month      string `json:"month"`                 // Format: YYYY-MM, defaults to the current month
+	ProjectID  int64  `json:"project_id,omitempty"`  // Only summarize bullets of this project
+	Template   string `json:"template,omitempty"`    // Built-in style: default, performance_review, resume_star, brag_document, manager_update
+	TemplateID int64  `json:"template_id,omitempty"` // A user-defined summary template, takes precedence over template
+	Async      bool   `json:"async,omitempty"`
+}
+
+// CreateSummaryRequest is the request body for generating a summary of any period
+type CreateSummaryRequest struct {
+	PeriodType string `json:"period_type"`           // week, month, quarter, year or custom
+	Period     string `json:"period,omitempty"`      // 2026-W42, 2026-10, 2026-Q4, 2026; defaults to the current one
+	Start      string `json:"start,omitempty"`       // Custom periods: YYYY-MM-DD, inclusive
+	End        string `json:"end,omitempty"`         // Custom periods: YYYY-MM-DD, inclusive
+	ProjectID  int64  `json:"project_id,omitempty"`  // Only summarize bullets of this project
+	Template   string `json:"template,omitempty"`    // Built-in style, see GenerateSummaryRequest
+	TemplateID int64  `json:"template_id,omitempty"` // A user-defined summary template
+	Async      bool   `json:"async,omitempty"`
+}
+
+// ListSummariesRequest holds the query parameters for listing summaries of a period type
+type ListSummariesRequest struct {
+	PeriodType string `query:"period_type"` // Defaults to month
+	ProjectID  int64  `query:"project_id"`
+	Limit      int    `query:"limit"`
+	Offset     int    `query:"offset"`
 }
 
 // WorkLogSummaryResponse is the response for a work log summary
 type WorkLogSummaryResponse struct {
+	ID          int64  `json:"id"`
+	ProjectID   int64  `json:"project_id,omitempty"`
+	Month       string `json:"month,omitempty"` // Only for monthly summaries
+	PeriodType  string `json:"period_type"`
+	Period      string `json:"period"`
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	Summary     string `json:"summary"`
+	Version     int    `json:"version"`
+	Pinned      bool   `json:"pinned"`
+	Strategy    string `json:"strategy,omitempty"` // single or map_reduce, empty for manual edits
+	Template    string `json:"template,omitempty"` // Style key or custom, empty for manual edits
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// WorkLogSummaryVersionResponse is the response for one version of a summary
+type WorkLogSummaryVersionResponse struct {
+	Version          int    `json:"version"`
+	Summary          string `json:"summary"`
+	Source           string `json:"source"`             // generated or manual
+	Strategy         string `json:"strategy,omitempty"` // single or map_reduce
+	Template         string `json:"template,omitempty"` // Style key or custom
+	TemplateID       int64  `json:"template_id,omitempty"`
+	TemplateVersion  int    `json:"template_version,omitempty"`
+	Provider         string `json:"provider,omitempty"`
+	Model            string `json:"model,omitempty"`
+	PromptHash       string `json:"prompt_hash,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	Current          bool   `json:"current"`
+	CreatedAt        string `json:"created_at"`
+}
+
+// WorkLogSummaryVersionListResponse is the response for listing the versions of a summary
+type WorkLogSummaryVersionListResponse struct {
+	Data []WorkLogSummaryVersionResponse `json:"data"`
+}
+
+// EditSummaryRequest is the request body for saving a hand-edited summary as a new version
+type EditSummaryRequest struct {
+	Summary string `json:"summary"`
+}
+
+// PinSummaryVersionRequest is the request body for pinning a summary version
+type PinSummaryVersionRequest struct {
+	Version int `json:"version"`
+}
+
+// SummaryStreamTokenData is the data of a token event of a streamed summary
+type SummaryStreamTokenData struct {
+	Content string `json:"content"`
+}
+
+// WorkLogSummaryListResponse is the response for listing summaries
+type WorkLogSummaryListResponse struct {
+	Data  []WorkLogSummaryResponse `json:"data"`
+	Total int                      `json:"total"`
+}
+
+// AutoSummaryRequest is the request body for toggling scheduled monthly summaries
+type AutoSummaryRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// AutoSummaryResponse is the response for the scheduled monthly summary opt-in
+type AutoSummaryResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// BackgroundJobResponse is the response for a background job
+type BackgroundJobResponse struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	Error       string          `json:"error,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	StartedAt   string          `json:"started_at,omitempty"`
+	FinishedAt  string          `json:"finished_at,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+	UpdatedAt   string          `json:"updated_at"`
+}
+
+// CreateWebhookRequest is the request body for creating a webhook subscription
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// UpdateWebhookRequest is the request body for updating a webhook subscription
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url,omitempty"`
+	Events []string `json:"events,omitempty"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// WebhookResponse is the response for a webhook subscription
+type WebhookResponse struct {
+	ID        int64    `json:"id"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"secret,omitempty"` // Only returned when the webhook is created
+	Events    []string `json:"events"`
+	Active    bool     `json:"active"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// WebhookListResponse is the response for listing webhook subscriptions
+type WebhookListResponse struct {
+	Data []WebhookResponse `json:"data"`
+}
+
+// WebhookDeliveryResponse is the response for a webhook delivery log entry
+type WebhookDeliveryResponse struct {
+	ID             int64           `json:"id"`
+	WebhookID      int64           `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+	UpdatedAt      string          `json:"updated_at"`
+}
+
+// WebhookDeliveryListResponse is the response for listing webhook deliveries
+type WebhookDeliveryListResponse struct {
+	Data []WebhookDeliveryResponse `json:"data"`
+}
+
+// WorkLogEventData is the event data for work_log.upserted
+type WorkLogEventData struct {
+	ID      int64  `json:"id"`
+	Date    string `json:"date"`
+	Content string `json:"content"`
+}
+
+// WorkLogDeletedEventData is the event data for work_log.deleted
+type WorkLogDeletedEventData struct {
+	Date string `json:"date"`
+}
+
+// JobApplicationStateChangedEventData is the event data for job_application.state_changed
+type JobApplicationStateChangedEventData struct {
+	ID          int64  `json:"id"`
+	CompanyName string `json:"company_name"`
+	JobTitle    string `json:"job_title"`
+	FromState   string `json:"from_state"`
+	ToState     string `json:"to_state"`
+}
+
+// SummaryGeneratedEventData is the event data for summary.generated
+type SummaryGeneratedEventData struct {
+	ID          int64  `json:"id"`
+	ProjectID   int64  `json:"project_id,omitempty"`
+	Month       string `json:"month,omitempty"` // Only for monthly summaries
+	PeriodType  string `json:"period_type"`
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+}
+
+// EventTicketResponse is the response for issuing an event stream ticket
+type EventTicketResponse struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// CreateProjectRequest is the request body for creating a project
+type CreateProjectRequest struct {
+	Name   string `json:"name"`
+	Tag    string `json:"tag,omitempty"`   // Defaults to a slug of the name
+	Color  string `json:"color,omitempty"` // Format: #RRGGBB
+	Client string `json:"client,omitempty"`
+}
+
+// UpdateProjectRequest is the request body for updating a project
+type UpdateProjectRequest struct {
+	Name     string  `json:"name,omitempty"`
+	Tag      string  `json:"tag,omitempty"`
+	Color    *string `json:"color,omitempty"`
+	Client   *string `json:"client,omitempty"`
+	Archived *bool   `json:"archived,omitempty"`
+}
+
+// ProjectResponse is the response for a project
+type ProjectResponse struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Tag       string `json:"tag"`
+	Color     string `json:"color,omitempty"`
+	Client    string `json:"client,omitempty"`
+	Archived  bool   `json:"archived"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// ProjectListResponse is the response for listing projects
+type ProjectListResponse struct {
+	Data []ProjectResponse `json:"data"`
+}
+
+// ProjectTimelineRequest holds the query parameters for a project timeline
+type ProjectTimelineRequest struct {
+	From   string `query:"from"` // Format: YYYY-MM-DD, inclusive
+	To     string `query:"to"`   // Format: YYYY-MM-DD, inclusive
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+	Order  string `query:"order"` // asc or desc (default)
+}
+
+// ProjectTimelineEntry lists the bullets attributed to a project on one day
+type ProjectTimelineEntry struct {
+	Date  string   `json:"date"`
+	Items []string `json:"items"`
+}
+
+// ProjectTimelineResponse is the response for a project timeline
+type ProjectTimelineResponse struct {
+	Project    ProjectResponse        `json:"project"`
+	Data       []ProjectTimelineEntry `json:"data"`
+	Total      int                    `json:"total"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+// StartTimerRequest is the request body for starting the timer
+type StartTimerRequest struct {
+	Text      string `json:"text"`
+	ProjectID int64  `json:"project_id,omitempty"`
+}
+
+// StopTimerRequest is the optional request body for stopping the timer
+type StopTimerRequest struct {
+	Text   string `json:"text,omitempty"` // Replaces the text given on start
+	Status string `json:"status,omitempty"`
+}
+
+// TimerResponse is the response for the running timer
+type TimerResponse struct {
+	ID             int64  `json:"id"`
+	Text           string `json:"text"`
+	ProjectID      int64  `json:"project_id,omitempty"`
+	StartedAt      string `json:"started_at"`
+	ElapsedSeconds int64  `json:"elapsed_seconds"`
+}
+
+// AppendTodayWorkLogItemResponse is the response for quick-appending an item to today's log
+type AppendTodayWorkLogItemResponse struct {
+	Date string              `json:"date"`
+	Item WorkLogItemResponse `json:"item"`
+}
+
+// StopTimerResponse is the response for stopping the timer
+type StopTimerResponse struct {
+	Date string              `json:"date"`
+	Item WorkLogItemResponse `json:"item"`
+}
+
+// TimesheetRequest holds the query parameters for a timesheet report
+type TimesheetRequest struct {
+	From    string `query:"from"`     // Format: YYYY-MM-DD, inclusive
+	To      string `query:"to"`       // Format: YYYY-MM-DD, inclusive
+	GroupBy string `query:"group_by"` // project (default), day or week
+	Format  string `query:"format"`   // json (default) or csv
+}
+
+// TimesheetRowResponse is the tracked time of one group
+type TimesheetRowResponse struct {
+	Key     string  `json:"key"`
+	Label   string  `json:"label"`
+	Minutes int     `json:"minutes"`
+	Hours   float64 `json:"hours"`
+	Items   int     `json:"items"`
+}
+
+// TimesheetResponse is the response for a timesheet report
+type TimesheetResponse struct {
+	From         string                 `json:"from"`
+	To           string                 `json:"to"`
+	GroupBy      string                 `json:"group_by"`
+	TotalMinutes int                    `json:"total_minutes"`
+	TotalHours   float64                `json:"total_hours"`
+	Data         []TimesheetRowResponse `json:"data"`
+}
+
+// WorkLogRevisionResponse is the response for a prior version of a work log
+type WorkLogRevisionResponse struct {
+	ID        int64  `json:"id"`
+	Date      string `json:"date"`
+	Content   string `json:"content"`
+	Reason    string `json:"reason"` // The change that replaced this content: update, delete or restore
+	CreatedAt string `json:"created_at"`
+}
+
+// WorkLogRevisionListResponse is the response for listing the revisions of a day
+type WorkLogRevisionListResponse struct {
+	Data []WorkLogRevisionResponse `json:"data"`
+}
+
+// DiffLineResponse is one line of a diff
+type DiffLineResponse struct {
+	Op   string `json:"op"` // equal, insert or delete
+	Text string `json:"text"`
+}
+
+// WorkLogRevisionDiffResponse is the response for comparing two versions of a day
+type WorkLogRevisionDiffResponse struct {
+	From    int64              `json:"from"`
+	To      int64              `json:"to,omitempty"` // Omitted when compared against the current content
+	Lines   []DiffLineResponse `json:"lines"`
+	Unified string             `json:"unified"`
+}
+
+// TrashedWorkLogResponse is the response for a work log in the trash
+type TrashedWorkLogResponse struct {
+	WorkLogResponse
+	DeletedAt string `json:"deleted_at"`
+	PurgeAt   string `json:"purge_at"`
+}
+
+// TrashedJobApplicationResponse is the response for a job application in the trash
+type TrashedJobApplicationResponse struct {
+	JobApplicationResponse
+	DeletedAt string `json:"deleted_at"`
+	PurgeAt   string `json:"purge_at"`
+}
+
+// TrashResponse is the response for listing the trash
+type TrashResponse struct {
+	WorkLogs        []TrashedWorkLogResponse        `json:"work_logs"`
+	JobApplications []TrashedJobApplicationResponse `json:"job_applications"`
+	RetentionDays   int                             `json:"retention_days"`
+}
+
+// WorkLogStatsRequest holds the query parameters for work log statistics
+type WorkLogStatsRequest struct {
+	Year int `query:"year"` // Defaults to the current year
+}
+
+// WorkLogDayStatResponse is one cell of the activity heatmap
+type WorkLogDayStatResponse struct {
+	Date  string `json:"date"`
+	Chars int    `json:"chars"`
+	Items int    `json:"items"`
+	Level int    `json:"level"` // 1-4, relative to the busiest day of the year
+}
+
+// WorkLogMonthStatResponse is the activity of one month
+type WorkLogMonthStatResponse struct {
+	Month string `json:"month"`
+	Days  int    `json:"days"`
+	Chars int    `json:"chars"`
+}
+
+// WorkLogStatsResponse is the response for work log statistics
+type WorkLogStatsResponse struct {
+	Year          int                        `json:"year"`
+	LoggedDays    int                        `json:"logged_days"`
+	CurrentStreak int                        `json:"current_streak"`
+	LongestStreak int                        `json:"longest_streak"`
+	Heatmap       []WorkLogDayStatResponse   `json:"heatmap"`
+	Months        []WorkLogMonthStatResponse `json:"months"`
+	TopTags       []WorkLogTagCountResponse  `json:"top_tags"`
+}
+
+// WorkdaysResponse lists the ISO weekdays (1 = Monday ... 7 = Sunday) a user works on
+type WorkdaysResponse struct {
+	Workdays []int `json:"workdays"`
+}
+
+// UpdateWorkdaysRequest is the request body for updating the working weekdays
+type UpdateWorkdaysRequest struct {
+	Workdays []int `json:"workdays"`
+}
+
+// CalendarDaysRequest holds the query parameters for listing holidays and leave
+type CalendarDaysRequest struct {
+	From string `query:"from"` // YYYY-MM-DD
+	To   string `query:"to"`   // YYYY-MM-DD
+	Kind string `query:"kind"` // holiday, leave; empty for both
+}
+
+// CreateCalendarDayRequest is the request body for adding a holiday or a leave day
+type CreateCalendarDayRequest struct {
+	Date string `json:"date"`
+	Kind string `json:"kind"` // holiday, leave
+	Name string `json:"name"`
+}
+
+// CalendarDayResponse is the response for a holiday or leave day
+type CalendarDayResponse struct {
 	ID        int64  `json:"id"`
-	Month     string `json:"month"`
-	Summary   string `json:"summary"`
+	Date      string `json:"date"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CalendarDayListResponse is the response for listing holidays and leave
+type CalendarDayListResponse struct {
+	Data []CalendarDayResponse `json:"data"`
+}
+
+// ImportHolidaysResponse is the response for an ICS holiday import
+type ImportHolidaysResponse struct {
+	Imported int                   `json:"imported"`
+	Data     []CalendarDayResponse `json:"data"`
+}
+
+// WorkLogGapsRequest holds the query parameters for finding missing work logs
+type WorkLogGapsRequest struct {
+	From string `query:"from"` // YYYY-MM-DD
+	To   string `query:"to"`   // YYYY-MM-DD
+}
+
+// WorkLogGapsResponse lists the working days without a work log
+type WorkLogGapsResponse struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Workdays int      `json:"workdays"`
+	Logged   int      `json:"logged"`
+	Dates    []string `json:"dates"`
+}
+
+// UserSettingsResponse is the response for the user settings
+type UserSettingsResponse struct {
+	Timezone     string `json:"timezone"`
+	Locale       string `json:"locale"`
+	WeekStart    string `json:"week_start"`
+	ExportFormat string `json:"export_format"`
+	AIProvider   string `json:"ai_provider"` // Empty uses the server default
+	UpdatedAt    string `json:"updated_at,omitempty"`
+}
+
+// UpdateUserSettingsRequest is the request body for PATCH /me/settings, omitted fields are kept
+type UpdateUserSettingsRequest struct {
+	Timezone     *string `json:"timezone"`
+	Locale       *string `json:"locale"`
+	WeekStart    *string `json:"week_start"`
+	ExportFormat *string `json:"export_format"`
+	AIProvider   *string `json:"ai_provider"`
+}
+
+// CreateWorkLogTemplateRequest is the request body for creating a daily log template
+type CreateWorkLogTemplateRequest struct {
+	Name      string `json:"name"`
+	Content   string `json:"content"` // Placeholders: {{date}}, {{weekday}}, {{previous_date}}, {{open_items}}
+	IsDefault bool   `json:"is_default,omitempty"`
+}
+
+// UpdateWorkLogTemplateRequest is the request body for updating a daily log template
+type UpdateWorkLogTemplateRequest struct {
+	Name      string  `json:"name,omitempty"`
+	Content   *string `json:"content,omitempty"`
+	IsDefault *bool   `json:"is_default,omitempty"`
+}
+
+// WorkLogTemplateResponse is the response for a daily log template
+type WorkLogTemplateResponse struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Content   string `json:"content"`
+	IsDefault bool   `json:"is_default"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
+
+// WorkLogTemplateListResponse is the response for listing daily log templates
+type WorkLogTemplateListResponse struct {
+	Data []WorkLogTemplateResponse `json:"data"`
+}
+
+// WorkLogTemplatePreviewResponse is a template rendered for a date
+type WorkLogTemplatePreviewResponse struct {
+	Date    string `json:"date"`
+	Content string `json:"content"`
+}
+
+// CreateWorkLogFromTemplateRequest is the request body for creating a day's log from a template
+type CreateWorkLogFromTemplateRequest struct {
+	TemplateID int64 `json:"template_id,omitempty"` // Defaults to the default template
+}
+
+// CreateSummaryTemplateRequest is the request body for creating a summary prompt template
+type CreateSummaryTemplateRequest struct {
+	Name    string `json:"name"`
+	Content string `json:"content"` // Placeholders: {{logs}} (required), {{period}}, {{period_adjective}}, {{project}}
+}
+
+// UpdateSummaryTemplateRequest is the request body for updating a summary prompt template
+type UpdateSummaryTemplateRequest struct {
+	Name    string  `json:"name,omitempty"`
+	Content *string `json:"content,omitempty"`
+}
+
+// SummaryTemplateResponse is the response for a summary prompt template, built-in or user-defined
+type SummaryTemplateResponse struct {
+	ID        int64  `json:"id,omitempty"` // Only for user-defined templates
+	Key       string `json:"key"`          // Style key, custom for user-defined templates
+	Name      string `json:"name"`
+	Content   string `json:"content"`
+	Version   int    `json:"version"`
+	BuiltIn   bool   `json:"built_in"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// SummaryTemplateListResponse is the response for listing summary prompt templates
+type SummaryTemplateListResponse struct {
+	Data []SummaryTemplateResponse `json:"data"`
+}
+
+// SummaryTemplateVersionResponse is the response for one version of a summary prompt template
+type SummaryTemplateVersionResponse struct {
+	Version   int    `json:"version"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+// SummaryTemplateVersionListResponse is the response for listing the versions of a summary prompt template
+type SummaryTemplateVersionListResponse struct {
+	Data []SummaryTemplateVersionResponse `json:"data"`
+}
+
+// StandupRequest holds the query parameters for a standup report
+type StandupRequest struct {
+	Date   string `query:"date"`   // YYYY-MM-DD, today, yesterday or -N; defaults to today
+	Format string `query:"format"` // markdown (default) or slack
+	Polish bool   `query:"polish"` // Rewrite the report through the AI provider
+}
+
+// StandupResponse is a standup report assembled from the previous working day and the given day
+type StandupResponse struct {
+	Date         string   `json:"date"`
+	PreviousDate string   `json:"previous_date"`
+	Format       string   `json:"format"`
+	Polished     bool     `json:"polished"`
+	Yesterday    []string `json:"yesterday"`
+	Today        []string `json:"today"`
+	Blockers     []string `json:"blockers"`
+	Text         string   `json:"text"`
+}
@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN auto_summary_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_users_auto_summary_enabled ON users(auto_summary_enabled) WHERE auto_summary_enabled;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_auto_summary_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS auto_summary_enabled;
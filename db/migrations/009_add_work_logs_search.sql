@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE work_logs
+  ADD COLUMN content_tsv TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', COALESCE(content, ''))) STORED;
+
+CREATE INDEX idx_work_logs_content_tsv ON work_logs USING GIN (content_tsv);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_work_logs_content_tsv;
+ALTER TABLE work_logs DROP COLUMN IF EXISTS content_tsv;
@@ -0,0 +1,27 @@
+-- +migrate Up
+CREATE TABLE work_log_tags (
+  id SERIAL PRIMARY KEY,
+  work_log_id INTEGER NOT NULL REFERENCES work_logs(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL CHECK (kind IN ('tag', 'mention')),
+  name TEXT NOT NULL,
+  occurrences INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  UNIQUE (work_log_id, kind, name)
+);
+
+CREATE INDEX idx_work_log_tags_user_id_kind_name ON work_log_tags(user_id, kind, name);
+
+-- Backfill existing logs with the same rules as work_log_tag_service.ParseTags
+INSERT INTO work_log_tags (work_log_id, user_id, kind, name, occurrences)
+SELECT wl.id, wl.user_id,
+       CASE WHEN m[2] = '#' THEN 'tag' ELSE 'mention' END,
+       rtrim(lower(m[3]), '-'),
+       COUNT(*)
+FROM work_logs wl,
+     regexp_matches(wl.content, '(^|[^[:alnum:]_])([#@])([A-Za-z][[:alnum:]_-]*)', 'g') AS m
+GROUP BY wl.id, wl.user_id, m[2], rtrim(lower(m[3]), '-')
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DROP TABLE IF EXISTS work_log_tags;
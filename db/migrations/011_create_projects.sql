@@ -0,0 +1,27 @@
+-- +migrate Up
+CREATE TABLE projects (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  tag TEXT NOT NULL,  -- bullets containing #tag are attributed to the project
+  color TEXT,
+  client TEXT,
+  archived BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  UNIQUE (user_id, tag)
+);
+
+CREATE INDEX idx_projects_user_id ON projects(user_id);
+
+-- Summaries can now be scoped to a project; NULL means the whole month
+ALTER TABLE work_log_summaries ADD COLUMN project_id INTEGER REFERENCES projects(id) ON DELETE CASCADE;
+ALTER TABLE work_log_summaries DROP CONSTRAINT work_log_summaries_user_id_month_key;
+CREATE UNIQUE INDEX idx_work_log_summaries_user_month_project ON work_log_summaries(user_id, month, COALESCE(project_id, 0));
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_work_log_summaries_user_month_project;
+DELETE FROM work_log_summaries WHERE project_id IS NOT NULL;
+ALTER TABLE work_log_summaries DROP COLUMN IF EXISTS project_id;
+ALTER TABLE work_log_summaries ADD CONSTRAINT work_log_summaries_user_id_month_key UNIQUE (user_id, month);
+DROP TABLE IF EXISTS projects;
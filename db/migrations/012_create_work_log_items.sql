@@ -0,0 +1,43 @@
+-- +migrate Up
+CREATE TABLE work_log_items (
+  id SERIAL PRIMARY KEY,
+  work_log_id INTEGER NOT NULL REFERENCES work_logs(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  text TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'done' CHECK (status IN ('done', 'in-progress', 'blocked')),
+  project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL,
+  duration_minutes INTEGER CHECK (duration_minutes > 0),
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_work_log_items_work_log_id_position ON work_log_items(work_log_id, position);
+CREATE INDEX idx_work_log_items_project_id ON work_log_items(project_id) WHERE project_id IS NOT NULL;
+
+-- Backfill one item per non-empty line, with the same rules as work_log_item_service.ParseItems
+WITH lines AS (
+  SELECT wl.id AS work_log_id, wl.user_id, l.ordinality,
+         regexp_replace(btrim(l.line), '^[-*•]\s+', '') AS line
+  FROM work_logs wl,
+       regexp_split_to_table(wl.content, '\n') WITH ORDINALITY AS l(line, ordinality)
+  WHERE btrim(l.line) <> ''
+), parsed AS (
+  SELECT work_log_id, user_id, ordinality,
+         CASE
+           WHEN line ~* '^\[(in-progress|wip)\]\s*' THEN 'in-progress'
+           WHEN line ~* '^\[blocked\]\s*' THEN 'blocked'
+           ELSE 'done'
+         END AS status,
+         regexp_replace(line, '^\[(done|in-progress|wip|blocked)\]\s*', '', 'i') AS text
+  FROM lines
+)
+INSERT INTO work_log_items (work_log_id, user_id, position, text, status)
+SELECT work_log_id, user_id,
+       ROW_NUMBER() OVER (PARTITION BY work_log_id ORDER BY ordinality) - 1,
+       text, status
+FROM parsed
+WHERE text <> '';
+
+-- +migrate Down
+DROP TABLE IF EXISTS work_log_items;
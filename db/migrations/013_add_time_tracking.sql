@@ -0,0 +1,21 @@
+-- +migrate Up
+ALTER TABLE work_log_items ADD COLUMN started_at TIMESTAMPTZ;
+ALTER TABLE work_log_items ADD COLUMN ended_at TIMESTAMPTZ;
+ALTER TABLE work_log_items ADD CONSTRAINT work_log_items_time_range_check
+  CHECK ((started_at IS NULL) = (ended_at IS NULL) AND (ended_at IS NULL OR ended_at > started_at));
+
+-- At most one running timer per user; stopping it turns it into a work log item
+CREATE TABLE timers (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+  text TEXT NOT NULL,
+  project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS timers;
+ALTER TABLE work_log_items DROP CONSTRAINT IF EXISTS work_log_items_time_range_check;
+ALTER TABLE work_log_items DROP COLUMN IF EXISTS ended_at;
+ALTER TABLE work_log_items DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Prior versions of a day, written before each overwrite, delete or restore
+CREATE TABLE work_log_revisions (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  content TEXT NOT NULL,
+  reason TEXT NOT NULL CHECK (reason IN ('update', 'delete', 'restore')),
+  created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_work_log_revisions_user_id_date ON work_log_revisions(user_id, date, id DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS work_log_revisions;
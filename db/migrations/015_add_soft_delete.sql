@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Deleted rows stay in the trash until restored or purged after the retention period
+ALTER TABLE work_logs ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE job_applications ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_work_logs_deleted_at ON work_logs(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_job_applications_deleted_at ON job_applications(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +migrate Down
+DELETE FROM work_logs WHERE deleted_at IS NOT NULL;
+DELETE FROM job_applications WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_job_applications_deleted_at;
+DROP INDEX IF EXISTS idx_work_logs_deleted_at;
+ALTER TABLE job_applications DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE work_logs DROP COLUMN IF EXISTS deleted_at;
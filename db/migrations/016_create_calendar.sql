@@ -0,0 +1,20 @@
+-- +migrate Up
+-- ISO weekdays the user works on (1 = Monday ... 7 = Sunday)
+ALTER TABLE users ADD COLUMN workdays INTEGER[] NOT NULL DEFAULT '{1,2,3,4,5}';
+
+CREATE TABLE calendar_days (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('holiday', 'leave')),
+  name TEXT NOT NULL DEFAULT '',
+  source TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'ics')),
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  UNIQUE (user_id, date, kind)
+);
+
+CREATE INDEX idx_calendar_days_user_date ON calendar_days(user_id, date);
+
+-- +migrate Down
+DROP TABLE IF EXISTS calendar_days;
+ALTER TABLE users DROP COLUMN IF EXISTS workdays;
@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE user_settings (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  timezone TEXT NOT NULL DEFAULT 'UTC',  -- IANA name, resolves "today" and month boundaries
+  locale TEXT NOT NULL DEFAULT 'en',
+  week_start TEXT NOT NULL DEFAULT 'monday' CHECK (week_start IN ('monday', 'sunday')),
+  export_format TEXT NOT NULL DEFAULT 'markdown' CHECK (export_format IN ('markdown', 'csv', 'json')),
+  ai_provider TEXT,  -- NULL uses the server default
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS user_settings;
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE work_log_templates (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  content TEXT NOT NULL,  -- may contain {{date}}, {{weekday}}, {{previous_date}} and {{open_items}}
+  is_default BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  UNIQUE (user_id, name)
+);
+
+-- At most one default template per user
+CREATE UNIQUE INDEX idx_work_log_templates_default ON work_log_templates(user_id) WHERE is_default;
+
+-- +migrate Down
+DROP TABLE IF EXISTS work_log_templates;
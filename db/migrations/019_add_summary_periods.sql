@@ -0,0 +1,36 @@
+-- +migrate Up
+-- Summaries cover a period (ISO week, month, quarter, year or custom range) instead of a month
+ALTER TABLE work_log_summaries
+  ADD COLUMN period_type TEXT NOT NULL DEFAULT 'month' CHECK (period_type IN ('week', 'month', 'quarter', 'year', 'custom')),
+  ADD COLUMN period_start DATE,
+  ADD COLUMN period_end DATE;
+
+UPDATE work_log_summaries
+SET period_start = TO_DATE(month, 'YYYY-MM'),
+    period_end = (TO_DATE(month, 'YYYY-MM') + INTERVAL '1 month' - INTERVAL '1 day')::date;
+
+ALTER TABLE work_log_summaries
+  ALTER COLUMN period_start SET NOT NULL,
+  ALTER COLUMN period_end SET NOT NULL,
+  ALTER COLUMN period_type DROP DEFAULT,
+  ADD CONSTRAINT work_log_summaries_period_check CHECK (period_end >= period_start);
+
+DROP INDEX IF EXISTS idx_work_log_summaries_user_month_project;
+DROP INDEX IF EXISTS idx_work_log_summaries_month;
+ALTER TABLE work_log_summaries DROP COLUMN month;
+
+CREATE UNIQUE INDEX idx_work_log_summaries_user_period_project
+  ON work_log_summaries(user_id, period_type, period_start, period_end, COALESCE(project_id, 0));
+CREATE INDEX idx_work_log_summaries_user_type_start ON work_log_summaries(user_id, period_type, period_start DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_work_log_summaries_user_type_start;
+DROP INDEX IF EXISTS idx_work_log_summaries_user_period_project;
+DELETE FROM work_log_summaries WHERE period_type <> 'month';
+ALTER TABLE work_log_summaries ADD COLUMN month VARCHAR(7);
+UPDATE work_log_summaries SET month = TO_CHAR(period_start, 'YYYY-MM');
+ALTER TABLE work_log_summaries ALTER COLUMN month SET NOT NULL;
+ALTER TABLE work_log_summaries DROP CONSTRAINT IF EXISTS work_log_summaries_period_check;
+ALTER TABLE work_log_summaries DROP COLUMN period_type, DROP COLUMN period_start, DROP COLUMN period_end;
+CREATE INDEX idx_work_log_summaries_month ON work_log_summaries(month);
+CREATE UNIQUE INDEX idx_work_log_summaries_user_month_project ON work_log_summaries(user_id, month, COALESCE(project_id, 0));
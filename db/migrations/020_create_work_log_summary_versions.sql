@@ -0,0 +1,34 @@
+-- +migrate Up
+-- Every generated or edited summary is kept as a version, the summary row shows the pinned
+-- version or else the latest one
+CREATE TABLE work_log_summary_versions (
+  id SERIAL PRIMARY KEY,
+  summary_id INTEGER NOT NULL REFERENCES work_log_summaries(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  summary TEXT NOT NULL,
+  source TEXT NOT NULL CHECK (source IN ('generated', 'manual')),
+  provider TEXT,
+  model TEXT,
+  prompt_hash TEXT,
+  prompt_tokens INTEGER NOT NULL DEFAULT 0,
+  completion_tokens INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  UNIQUE (summary_id, version)
+);
+
+ALTER TABLE work_log_summaries
+  ADD COLUMN current_version_id INTEGER REFERENCES work_log_summary_versions(id) ON DELETE SET NULL,
+  ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Existing summaries become their first version
+INSERT INTO work_log_summary_versions (summary_id, version, summary, source, created_at)
+SELECT id, 1, summary, 'generated', updated_at FROM work_log_summaries;
+
+UPDATE work_log_summaries s
+SET current_version_id = v.id
+FROM work_log_summary_versions v
+WHERE v.summary_id = s.id;
+
+-- +migrate Down
+ALTER TABLE work_log_summaries DROP COLUMN IF EXISTS pinned, DROP COLUMN IF EXISTS current_version_id;
+DROP TABLE IF EXISTS work_log_summary_versions;
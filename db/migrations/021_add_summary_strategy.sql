@@ -0,0 +1,10 @@
+-- +migrate Up
+-- How a generated version was produced: single (one prompt for the whole period) or
+-- map_reduce (weekly summaries summarized again). Manual edits have none.
+ALTER TABLE work_log_summary_versions
+  ADD COLUMN strategy TEXT CHECK (strategy IN ('single', 'map_reduce'));
+
+UPDATE work_log_summary_versions SET strategy = 'single' WHERE source = 'generated';
+
+-- +migrate Down
+ALTER TABLE work_log_summary_versions DROP COLUMN IF EXISTS strategy;
@@ -0,0 +1,36 @@
+-- +migrate Up
+-- User-defined summary prompt templates, built-in styles live in code
+CREATE TABLE summary_templates (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  content TEXT NOT NULL,  -- may contain {{logs}}, {{period}}, {{period_adjective}} and {{project}}
+  version INTEGER NOT NULL DEFAULT 1,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  UNIQUE (user_id, name)
+);
+
+-- Every content a template had, so summaries can tell which one produced them
+CREATE TABLE summary_template_versions (
+  template_id INTEGER NOT NULL REFERENCES summary_templates(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  content TEXT NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  PRIMARY KEY (template_id, version)
+);
+
+ALTER TABLE work_log_summary_versions
+  ADD COLUMN template TEXT,  -- Built-in style key, or custom
+  ADD COLUMN template_id INTEGER REFERENCES summary_templates(id) ON DELETE SET NULL,
+  ADD COLUMN template_version INTEGER;
+
+UPDATE work_log_summary_versions SET template = 'default', template_version = 1 WHERE source = 'generated';
+
+-- +migrate Down
+ALTER TABLE work_log_summary_versions
+  DROP COLUMN IF EXISTS template_version,
+  DROP COLUMN IF EXISTS template_id,
+  DROP COLUMN IF EXISTS template;
+DROP TABLE IF EXISTS summary_template_versions;
+DROP TABLE IF EXISTS summary_templates;
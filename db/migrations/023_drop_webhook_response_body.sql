@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Response bodies of webhook targets are no longer stored, only their status
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS response_body;
+
+-- +migrate Down
+ALTER TABLE webhook_deliveries ADD COLUMN response_body TEXT;
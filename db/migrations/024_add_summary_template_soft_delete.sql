@@ -0,0 +1,13 @@
+-- +migrate Up
+-- Deleted templates are kept with their versions so summaries stay traceable,
+-- their names become free for new templates
+ALTER TABLE summary_templates ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE summary_templates DROP CONSTRAINT summary_templates_user_id_name_key;
+CREATE UNIQUE INDEX idx_summary_templates_user_name ON summary_templates(user_id, name) WHERE deleted_at IS NULL;
+
+-- +migrate Down
+DELETE FROM summary_templates WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_summary_templates_user_name;
+ALTER TABLE summary_templates ADD CONSTRAINT summary_templates_user_id_name_key UNIQUE (user_id, name);
+ALTER TABLE summary_templates DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,61 @@
+package background_job_handler
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/background_job_service"
+	"worknote-api/utils/render"
+)
+
+// ToBackgroundJobResponse converts a model to response, shared by handlers that enqueue jobs
+func ToBackgroundJobResponse(job *model.BackgroundJob) contract.BackgroundJobResponse {
+	resp := contract.BackgroundJobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   job.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if job.Result != "" {
+		resp.Result = json.RawMessage(job.Result)
+	}
+	if job.StartedAt != nil {
+		resp.StartedAt = job.StartedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if job.FinishedAt != nil {
+		resp.FinishedAt = job.FinishedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+// GetJob handles GET /jobs/:id
+func GetJob(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	job, err := background_job_service.GetJob(id, userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if job == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, ToBackgroundJobResponse(job))
+}
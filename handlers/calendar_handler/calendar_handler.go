@@ -0,0 +1,184 @@
+package calendar_handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/calendar_service"
+	"worknote-api/utils/render"
+)
+
+// toCalendarDayResponse converts a model to response
+func toCalendarDayResponse(day *model.CalendarDay) contract.CalendarDayResponse {
+	return contract.CalendarDayResponse{
+		ID:        day.ID,
+		Date:      day.Date,
+		Kind:      day.Kind,
+		Name:      day.Name,
+		Source:    day.Source,
+		CreatedAt: day.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func toCalendarDayResponses(days []model.CalendarDay) []contract.CalendarDayResponse {
+	responses := make([]contract.CalendarDayResponse, len(days))
+	for i, day := range days {
+		responses[i] = toCalendarDayResponse(&day)
+	}
+	return responses
+}
+
+// GetWorkdays handles GET /me/calendar/workdays
+func GetWorkdays(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	workdays, err := calendar_service.GetWorkdays(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkdaysResponse{Workdays: workdays})
+}
+
+// UpdateWorkdays handles PUT /me/calendar/workdays
+func UpdateWorkdays(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.UpdateWorkdaysRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	workdays, err := calendar_service.UpdateWorkdays(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkdaysResponse{Workdays: workdays})
+}
+
+// ListDays handles GET /me/calendar/days
+func ListDays(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CalendarDaysRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+
+	days, err := calendar_service.ListDays(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.CalendarDayListResponse{
+		Data: toCalendarDayResponses(days),
+	})
+}
+
+// CreateDay handles POST /me/calendar/days
+func CreateDay(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CreateCalendarDayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	day, err := calendar_service.AddDay(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusCreated, toCalendarDayResponse(day))
+}
+
+// DeleteDay handles DELETE /me/calendar/days/:id
+func DeleteDay(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	if err := calendar_service.DeleteDay(userInfo.UserID, id); err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ImportHolidays handles POST /me/calendar/holidays/import with an ICS file in the "file" field
+func ImportHolidays(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return render.BadRequest(c, "file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "failed to open file")
+	}
+	defer file.Close()
+
+	days, err := calendar_service.ImportHolidays(userInfo.UserID, file)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.ImportHolidaysResponse{
+		Imported: len(days),
+		Data:     toCalendarDayResponses(days),
+	})
+}
+
+// GetWorkLogGaps handles GET /work-logs/gaps
+func GetWorkLogGaps(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.WorkLogGapsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+
+	gaps, err := calendar_service.ListGaps(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogGapsResponse{
+		From:     gaps.From,
+		To:       gaps.To,
+		Workdays: gaps.Workdays,
+		Logged:   gaps.Logged,
+		Dates:    gaps.Dates,
+	})
+}
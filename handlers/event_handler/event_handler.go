@@ -0,0 +1,90 @@
+package event_handler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/contract"
+	"worknote-api/middleware"
+	"worknote-api/services/realtime_service"
+	"worknote-api/utils/render"
+)
+
+// heartbeatInterval keeps idle streams alive through proxies
+const heartbeatInterval = 25 * time.Second
+
+// CreateTicket handles POST /events/ticket
+func CreateTicket(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	ticket, err := realtime_service.IssueTicket(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return render.JSON(c, fiber.StatusCreated, contract.EventTicketResponse{
+		Ticket:    ticket,
+		ExpiresIn: int(realtime_service.TicketTTL.Seconds()),
+	})
+}
+
+// Stream handles GET /events/stream
+func Stream(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := realtime_service.Subscribe(ctx, userInfo.UserID)
+	if err != nil {
+		cancel()
+		return render.Error(c, fiber.StatusInternalServerError, "failed to subscribe to events")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		defer sub.Close()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		fmt.Fprint(w, "retry: 3000\n: connected\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case msg, ok := <-sub.Messages:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, msg.Data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// A failed flush means the client went away
+			if err := w.Flush(); err != nil {
+				log.Debugf("event stream closed for user %d: %v", userInfo.UserID, err)
+				return
+			}
+		}
+	})
+
+	return nil
+}
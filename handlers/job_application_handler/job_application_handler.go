@@ -12,8 +12,8 @@ import (
 	"worknote-api/utils/render"
 )
 
-// toJobApplicationResponse converts a model to response
-func toJobApplicationResponse(app *model.JobApplication) contract.JobApplicationResponse {
+// ToJobApplicationResponse converts a model to response
+func ToJobApplicationResponse(app *model.JobApplication) contract.JobApplicationResponse {
 	return contract.JobApplicationResponse{
 		ID:          app.ID,
 		CompanyName: app.CompanyName,
@@ -58,7 +58,7 @@ func CreateJobApplication(c *fiber.Ctx) error {
 		return render.BadRequest(c, err.Error())
 	}
 
-	return render.JSON(c, fiber.StatusCreated, toJobApplicationResponse(app))
+	return render.JSON(c, fiber.StatusCreated, ToJobApplicationResponse(app))
 }
 
 // GetJobApplication handles GET /job-applications/:id
@@ -81,7 +81,7 @@ func GetJobApplication(c *fiber.Ctx) error {
 		return render.Error(c, fiber.StatusNotFound, "not found")
 	}
 
-	return render.JSON(c, fiber.StatusOK, toJobApplicationResponse(app))
+	return render.JSON(c, fiber.StatusOK, ToJobApplicationResponse(app))
 }
 
 // ListJobApplications handles GET /job-applications
@@ -103,7 +103,7 @@ func ListJobApplications(c *fiber.Ctx) error {
 
 	responses := make([]contract.JobApplicationResponse, len(apps))
 	for i, app := range apps {
-		responses[i] = toJobApplicationResponse(&app)
+		responses[i] = ToJobApplicationResponse(&app)
 	}
 
 	return render.JSON(c, fiber.StatusOK, contract.JobApplicationListResponse{
@@ -137,7 +137,7 @@ func UpdateJobApplication(c *fiber.Ctx) error {
 		return render.Error(c, fiber.StatusNotFound, "not found")
 	}
 
-	return render.JSON(c, fiber.StatusOK, toJobApplicationResponse(app))
+	return render.JSON(c, fiber.StatusOK, ToJobApplicationResponse(app))
 }
 
 // DeleteJobApplication handles DELETE /job-applications/:id
@@ -0,0 +1,172 @@
+package project_handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/project_service"
+	"worknote-api/utils/render"
+)
+
+// toProjectResponse converts a model to response
+func toProjectResponse(project *model.Project) contract.ProjectResponse {
+	return contract.ProjectResponse{
+		ID:        project.ID,
+		Name:      project.Name,
+		Tag:       project.Tag,
+		Color:     project.Color,
+		Client:    project.Client,
+		Archived:  project.Archived,
+		CreatedAt: project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// CreateProject handles POST /projects
+func CreateProject(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CreateProjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	project, err := project_service.CreateProject(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusCreated, toProjectResponse(project))
+}
+
+// ListProjects handles GET /projects
+func ListProjects(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	projects, err := project_service.ListProjects(userInfo.UserID, c.QueryBool("include_archived", false))
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	responses := make([]contract.ProjectResponse, len(projects))
+	for i, project := range projects {
+		responses[i] = toProjectResponse(&project)
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.ProjectListResponse{
+		Data: responses,
+	})
+}
+
+// GetProject handles GET /projects/:id
+func GetProject(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	project, err := project_service.GetProject(id, userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if project == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toProjectResponse(project))
+}
+
+// UpdateProject handles PUT /projects/:id
+func UpdateProject(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	var req contract.UpdateProjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	project, err := project_service.UpdateProject(id, userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if project == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toProjectResponse(project))
+}
+
+// DeleteProject handles DELETE /projects/:id
+func DeleteProject(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	if err := project_service.DeleteProject(id, userInfo.UserID); err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetProjectTimeline handles GET /projects/:id/timeline
+func GetProjectTimeline(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	var req contract.ProjectTimelineRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+
+	timeline, err := project_service.GetTimeline(id, userInfo.UserID, &req, middleware.IsCacheBypassed(c))
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if timeline == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.ProjectTimelineResponse{
+		Project:    toProjectResponse(timeline.Project),
+		Data:       timeline.Entries,
+		Total:      timeline.Total,
+		NextCursor: timeline.NextCursor,
+	})
+}
@@ -0,0 +1,70 @@
+package report_handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/middleware"
+	"worknote-api/services/standup_service"
+	"worknote-api/services/timesheet_service"
+	"worknote-api/utils/render"
+)
+
+// GetTimesheet handles GET /reports/timesheet
+func GetTimesheet(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.TimesheetRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+	if req.Format != "" && req.Format != "json" && req.Format != "csv" {
+		return render.BadRequest(c, "format must be json or csv")
+	}
+
+	timesheet, err := timesheet_service.GetTimesheet(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	if req.Format == "csv" {
+		body, err := timesheet_service.RenderCSV(timesheet)
+		if err != nil {
+			return render.Error(c, fiber.StatusInternalServerError, "internal error")
+		}
+		c.Set("Content-Type", "text/csv; charset=utf-8")
+		c.Set("Content-Disposition", "attachment; filename=\""+timesheet_service.GenerateFilename(timesheet)+"\"")
+		return c.Send(body)
+	}
+
+	return render.JSON(c, fiber.StatusOK, timesheet)
+}
+
+// GetStandup handles GET /reports/standup
+func GetStandup(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.StandupRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+
+	standup, err := standup_service.GetStandup(userInfo.UserID, &req)
+	if err == standup_service.ErrInvalidFormat || err == standup_service.ErrInvalidDate {
+		return render.BadRequest(c, err.Error())
+	}
+	if err == standup_service.ErrPolishFailed {
+		return render.Error(c, fiber.StatusBadGateway, err.Error())
+	}
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return render.JSON(c, fiber.StatusOK, standup)
+}
@@ -0,0 +1,182 @@
+package summary_template_handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/summary_template_service"
+	"worknote-api/utils/render"
+)
+
+// toSummaryTemplateResponse converts a model to response
+func toSummaryTemplateResponse(template *model.SummaryTemplate) contract.SummaryTemplateResponse {
+	return contract.SummaryTemplateResponse{
+		ID:        template.ID,
+		Key:       summary_template_service.KeyCustom,
+		Name:      template.Name,
+		Content:   template.Content,
+		Version:   template.Version,
+		CreatedAt: template.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: template.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// CreateSummaryTemplate handles POST /me/summary-templates
+func CreateSummaryTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CreateSummaryTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	template, err := summary_template_service.CreateTemplate(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusCreated, toSummaryTemplateResponse(template))
+}
+
+// ListSummaryTemplates handles GET /me/summary-templates, built-in styles first
+func ListSummaryTemplates(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	templates, err := summary_template_service.ListTemplates(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	builtins := summary_template_service.Builtins()
+	responses := make([]contract.SummaryTemplateResponse, 0, len(builtins)+len(templates))
+	for _, builtin := range builtins {
+		responses = append(responses, contract.SummaryTemplateResponse{
+			Key:     builtin.Key,
+			Name:    builtin.Name,
+			Content: builtin.Content,
+			Version: builtin.Version,
+			BuiltIn: true,
+		})
+	}
+	for _, template := range templates {
+		responses = append(responses, toSummaryTemplateResponse(&template))
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.SummaryTemplateListResponse{
+		Data: responses,
+	})
+}
+
+// GetSummaryTemplate handles GET /me/summary-templates/:id
+func GetSummaryTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	template, err := summary_template_service.GetTemplate(id, userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if template == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toSummaryTemplateResponse(template))
+}
+
+// UpdateSummaryTemplate handles PUT /me/summary-templates/:id
+func UpdateSummaryTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	var req contract.UpdateSummaryTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	template, err := summary_template_service.UpdateTemplate(id, userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if template == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toSummaryTemplateResponse(template))
+}
+
+// DeleteSummaryTemplate handles DELETE /me/summary-templates/:id
+func DeleteSummaryTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	if err := summary_template_service.DeleteTemplate(id, userInfo.UserID); err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListSummaryTemplateVersions handles GET /me/summary-templates/:id/versions
+func ListSummaryTemplateVersions(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	versions, err := summary_template_service.ListTemplateVersions(id, userInfo.UserID)
+	if err == summary_template_service.ErrTemplateNotFound {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	responses := make([]contract.SummaryTemplateVersionResponse, len(versions))
+	for i, version := range versions {
+		responses[i] = contract.SummaryTemplateVersionResponse{
+			Version:   version.Version,
+			Content:   version.Content,
+			CreatedAt: version.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.SummaryTemplateVersionListResponse{
+		Data: responses,
+	})
+}
@@ -0,0 +1,95 @@
+package timer_handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/handlers/work_log_handler"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/timer_service"
+	"worknote-api/utils/render"
+)
+
+// toTimerResponse converts a model to response
+func toTimerResponse(timer *model.Timer) contract.TimerResponse {
+	return contract.TimerResponse{
+		ID:             timer.ID,
+		Text:           timer.Text,
+		ProjectID:      timer.ProjectID,
+		StartedAt:      timer.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ElapsedSeconds: int64(time.Since(timer.StartedAt).Seconds()),
+	}
+}
+
+// StartTimer handles POST /timers/start
+func StartTimer(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.StartTimerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	timer, err := timer_service.StartTimer(userInfo.UserID, &req)
+	if err == timer_service.ErrTimerRunning {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusCreated, toTimerResponse(timer))
+}
+
+// GetTimer handles GET /timers/current
+func GetTimer(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	timer, err := timer_service.GetTimer(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if timer == nil {
+		return render.Error(c, fiber.StatusNotFound, "no timer is running")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toTimerResponse(timer))
+}
+
+// StopTimer handles POST /timers/stop
+func StopTimer(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	// The body is optional
+	var req contract.StopTimerRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return render.BadRequest(c, "invalid request body")
+		}
+	}
+
+	date, item, err := timer_service.StopTimer(userInfo.UserID, &req)
+	if err == timer_service.ErrNoTimer {
+		return render.Error(c, fiber.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.StopTimerResponse{
+		Date: date,
+		Item: work_log_handler.ToWorkLogItemResponse(item),
+	})
+}
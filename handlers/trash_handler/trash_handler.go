@@ -0,0 +1,131 @@
+package trash_handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/config"
+	"worknote-api/contract"
+	"worknote-api/handlers/job_application_handler"
+	"worknote-api/handlers/work_log_handler"
+	"worknote-api/middleware"
+	"worknote-api/services/trash_service"
+	"worknote-api/utils/render"
+)
+
+// ListTrash handles GET /trash
+func ListTrash(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	trash, err := trash_service.ListTrash(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	resp := contract.TrashResponse{
+		WorkLogs:        make([]contract.TrashedWorkLogResponse, len(trash.WorkLogs)),
+		JobApplications: make([]contract.TrashedJobApplicationResponse, len(trash.JobApplications)),
+		RetentionDays:   config.Get().TrashRetentionDays,
+	}
+	for i, workLog := range trash.WorkLogs {
+		resp.WorkLogs[i] = contract.TrashedWorkLogResponse{
+			WorkLogResponse: work_log_handler.ToWorkLogResponse(&workLog),
+			DeletedAt:       workLog.DeletedAt.Format("2006-01-02T15:04:05Z07:00"),
+			PurgeAt:         trash_service.PurgeAt(*workLog.DeletedAt).Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+	for i, app := range trash.JobApplications {
+		resp.JobApplications[i] = contract.TrashedJobApplicationResponse{
+			JobApplicationResponse: job_application_handler.ToJobApplicationResponse(&app),
+			DeletedAt:              app.DeletedAt.Format("2006-01-02T15:04:05Z07:00"),
+			PurgeAt:                trash_service.PurgeAt(*app.DeletedAt).Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+
+	return render.JSON(c, fiber.StatusOK, resp)
+}
+
+// RestoreWorkLog handles POST /trash/work-logs/:date/restore
+func RestoreWorkLog(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	workLog, err := trash_service.RestoreWorkLog(userInfo.UserID, c.Params("date"))
+	if err == trash_service.ErrInvalidDate {
+		return render.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if workLog == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found in trash")
+	}
+
+	return render.JSON(c, fiber.StatusOK, work_log_handler.ToWorkLogResponse(workLog))
+}
+
+// PurgeWorkLog handles DELETE /trash/work-logs/:date
+func PurgeWorkLog(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	err := trash_service.PurgeWorkLog(userInfo.UserID, c.Params("date"))
+	if err == trash_service.ErrInvalidDate {
+		return render.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RestoreJobApplication handles POST /trash/job-applications/:id/restore
+func RestoreJobApplication(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	app, err := trash_service.RestoreJobApplication(id, userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if app == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found in trash")
+	}
+
+	return render.JSON(c, fiber.StatusOK, job_application_handler.ToJobApplicationResponse(app))
+}
+
+// PurgeJobApplication handles DELETE /trash/job-applications/:id
+func PurgeJobApplication(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	if err := trash_service.PurgeJobApplication(id, userInfo.UserID); err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
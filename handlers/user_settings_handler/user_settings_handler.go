@@ -0,0 +1,61 @@
+package user_settings_handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/user_settings_service"
+	"worknote-api/utils/render"
+)
+
+// toUserSettingsResponse converts a model to response
+func toUserSettingsResponse(settings *model.UserSettings) contract.UserSettingsResponse {
+	resp := contract.UserSettingsResponse{
+		Timezone:     settings.Timezone,
+		Locale:       settings.Locale,
+		WeekStart:    settings.WeekStart,
+		ExportFormat: settings.ExportFormat,
+		AIProvider:   settings.AIProvider,
+	}
+	if !settings.UpdatedAt.IsZero() {
+		resp.UpdatedAt = settings.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+// GetSettings handles GET /me/settings
+func GetSettings(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	settings, err := user_settings_service.GetSettings(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toUserSettingsResponse(settings))
+}
+
+// UpdateSettings handles PATCH /me/settings
+func UpdateSettings(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.UpdateUserSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	settings, err := user_settings_service.UpdateSettings(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, toUserSettingsResponse(settings))
+}
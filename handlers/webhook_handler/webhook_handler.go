@@ -0,0 +1,226 @@
+package webhook_handler
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/webhook_service"
+	"worknote-api/utils/render"
+)
+
+// toWebhookResponse converts a model to response
+func toWebhookResponse(webhook *model.Webhook) contract.WebhookResponse {
+	events := []string(webhook.Events)
+	if events == nil {
+		events = []string{}
+	}
+	return contract.WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    events,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: webhook.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// toWebhookDeliveryResponse converts a model to response
+func toWebhookDeliveryResponse(delivery *model.WebhookDelivery) contract.WebhookDeliveryResponse {
+	resp := contract.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		Event:          delivery.Event,
+		Payload:        json.RawMessage(delivery.Payload),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:      delivery.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if delivery.DeliveredAt != nil {
+		resp.DeliveredAt = delivery.DeliveredAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+// CreateWebhook handles POST /me/webhooks
+func CreateWebhook(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	webhook, err := webhook_service.CreateWebhook(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	// The secret is only shown once, at creation
+	resp := toWebhookResponse(webhook)
+	resp.Secret = webhook.Secret
+	return render.JSON(c, fiber.StatusCreated, resp)
+}
+
+// ListWebhooks handles GET /me/webhooks
+func ListWebhooks(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	webhooks, err := webhook_service.ListWebhooks(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	responses := make([]contract.WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = toWebhookResponse(&webhook)
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WebhookListResponse{
+		Data: responses,
+	})
+}
+
+// GetWebhook handles GET /me/webhooks/:id
+func GetWebhook(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	webhook, err := webhook_service.GetWebhook(id, userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if webhook == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toWebhookResponse(webhook))
+}
+
+// UpdateWebhook handles PUT /me/webhooks/:id
+func UpdateWebhook(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	var req contract.UpdateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	webhook, err := webhook_service.UpdateWebhook(id, userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if webhook == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toWebhookResponse(webhook))
+}
+
+// DeleteWebhook handles DELETE /me/webhooks/:id
+func DeleteWebhook(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	if err := webhook_service.DeleteWebhook(id, userInfo.UserID); err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles GET /me/webhooks/:id/deliveries
+func ListWebhookDeliveries(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	deliveries, err := webhook_service.ListDeliveries(id, userInfo.UserID, limit, offset)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if deliveries == nil {
+		return render.Error(c, fiber.StatusNotFound, "webhook not found")
+	}
+
+	responses := make([]contract.WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = toWebhookDeliveryResponse(&delivery)
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WebhookDeliveryListResponse{
+		Data: responses,
+	})
+}
+
+// RedeliverWebhookDelivery handles POST /me/webhooks/:id/deliveries/:delivery_id/redeliver
+func RedeliverWebhookDelivery(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	deliveryID, err := strconv.ParseInt(c.Params("delivery_id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid delivery_id")
+	}
+
+	delivery, err := webhook_service.Redeliver(id, deliveryID, userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if delivery == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusAccepted, toWebhookDeliveryResponse(delivery))
+}
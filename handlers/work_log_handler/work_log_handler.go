@@ -1,19 +1,26 @@
 package work_log_handler
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 
 	"worknote-api/contract"
+	"worknote-api/handlers/background_job_handler"
 	"worknote-api/middleware"
 	"worknote-api/model"
 	"worknote-api/services/work_log_download_service"
 	"worknote-api/services/work_log_import_service"
+	"worknote-api/services/work_log_search_service"
 	"worknote-api/services/work_log_service"
+	"worknote-api/services/work_log_stats_service"
+	"worknote-api/services/work_log_tag_service"
 	"worknote-api/utils/render"
 )
 
-// toWorkLogResponse converts a model to response
-func toWorkLogResponse(log *model.WorkLog) contract.WorkLogResponse {
+// ToWorkLogResponse converts a model to response
+func ToWorkLogResponse(log *model.WorkLog) contract.WorkLogResponse {
 	return contract.WorkLogResponse{
 		ID:        log.ID,
 		Date:      log.Date,
@@ -36,11 +43,14 @@ func UpsertWorkLog(c *fiber.Ctx) error {
 	}
 
 	workLog, err := work_log_service.UpsertWorkLog(userInfo.UserID, &req)
+	if err == work_log_service.ErrWorkLogTrashed {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
 	if err != nil {
 		return render.BadRequest(c, err.Error())
 	}
 
-	return render.JSON(c, fiber.StatusOK, toWorkLogResponse(workLog))
+	return render.JSON(c, fiber.StatusOK, ToWorkLogResponse(workLog))
 }
 
 // GetWorkLogByDate handles GET /work-logs/:date
@@ -55,7 +65,7 @@ func GetWorkLogByDate(c *fiber.Ctx) error {
 		return render.BadRequest(c, "date is required")
 	}
 
-	workLog, err := work_log_service.GetWorkLogByDate(userInfo.UserID, date)
+	workLog, err := work_log_service.GetWorkLogByDate(userInfo.UserID, date, middleware.IsCacheBypassed(c))
 	if err != nil {
 		return render.Error(c, fiber.StatusInternalServerError, "internal error")
 	}
@@ -63,7 +73,187 @@ func GetWorkLogByDate(c *fiber.Ctx) error {
 		return render.Error(c, fiber.StatusNotFound, "not found")
 	}
 
-	return render.JSON(c, fiber.StatusOK, toWorkLogResponse(workLog))
+	return render.JSON(c, fiber.StatusOK, ToWorkLogResponse(workLog))
+}
+
+// ToWorkLogItemResponse converts a model to response
+func ToWorkLogItemResponse(item *model.WorkLogItem) contract.WorkLogItemResponse {
+	resp := contract.WorkLogItemResponse{
+		ID:              item.ID,
+		Position:        item.Position,
+		Text:            item.Text,
+		Status:          item.Status,
+		ProjectID:       item.ProjectID,
+		DurationMinutes: item.DurationMinutes,
+		CreatedAt:       item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:       item.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if item.StartedAt != nil && item.EndedAt != nil {
+		resp.StartedAt = item.StartedAt.Format("2006-01-02T15:04:05Z07:00")
+		resp.EndedAt = item.EndedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+// toWorkLogItemListResponse converts the items of a day to response
+func toWorkLogItemListResponse(items []model.WorkLogItem, content string) contract.WorkLogItemListResponse {
+	responses := make([]contract.WorkLogItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = ToWorkLogItemResponse(&item)
+	}
+	return contract.WorkLogItemListResponse{
+		Data:    responses,
+		Content: content,
+	}
+}
+
+// ListWorkLogItems handles GET /work-logs/:date/items
+func ListWorkLogItems(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	items, content, err := work_log_service.ListWorkLogItems(userInfo.UserID, c.Params("date"))
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, toWorkLogItemListResponse(items, content))
+}
+
+// CreateWorkLogItem handles POST /work-logs/:date/items
+func CreateWorkLogItem(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CreateWorkLogItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	item, err := work_log_service.AddWorkLogItem(userInfo.UserID, c.Params("date"), &req)
+	if err == work_log_service.ErrWorkLogTrashed {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusCreated, ToWorkLogItemResponse(item))
+}
+
+// AppendTodayWorkLogItem handles POST /work-logs/today/items. Besides the JSON body of
+// CreateWorkLogItem, a text/plain body is taken as the item text.
+func AppendTodayWorkLogItem(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CreateWorkLogItemRequest
+	if strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMETextPlain) {
+		req.Text = strings.TrimSpace(string(c.Body()))
+	} else if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	date, err := work_log_service.ResolveDate(userInfo.UserID, "today")
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	item, err := work_log_service.AddWorkLogItem(userInfo.UserID, date, &req)
+	if err == work_log_service.ErrWorkLogTrashed {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusCreated, contract.AppendTodayWorkLogItemResponse{
+		Date: date,
+		Item: ToWorkLogItemResponse(item),
+	})
+}
+
+// UpdateWorkLogItem handles PUT /work-logs/:date/items/:item_id
+func UpdateWorkLogItem(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	itemID, err := strconv.ParseInt(c.Params("item_id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid item_id")
+	}
+
+	var req contract.UpdateWorkLogItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	item, err := work_log_service.UpdateWorkLogItem(userInfo.UserID, c.Params("date"), itemID, &req)
+	if err == work_log_service.ErrWorkLogTrashed {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if item == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, ToWorkLogItemResponse(item))
+}
+
+// DeleteWorkLogItem handles DELETE /work-logs/:date/items/:item_id
+func DeleteWorkLogItem(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	itemID, err := strconv.ParseInt(c.Params("item_id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid item_id")
+	}
+
+	err = work_log_service.DeleteWorkLogItem(userInfo.UserID, c.Params("date"), itemID)
+	if err == work_log_service.ErrWorkLogTrashed {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, map[string]string{"message": "deleted"})
+}
+
+// ReorderWorkLogItems handles PUT /work-logs/:date/items/reorder
+func ReorderWorkLogItems(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.ReorderWorkLogItemsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	items, content, err := work_log_service.ReorderWorkLogItems(userInfo.UserID, c.Params("date"), req.ItemIDs)
+	if err == work_log_service.ErrWorkLogTrashed {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, toWorkLogItemListResponse(items, content))
 }
 
 // ListWorkLogs handles GET /work-logs
@@ -73,21 +263,151 @@ func ListWorkLogs(c *fiber.Ctx) error {
 		return render.Unauthorized(c, "unauthorized")
 	}
 
-	logs, err := work_log_service.ListWorkLogs(userInfo.UserID)
+	var req contract.ListWorkLogsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+
+	page, err := work_log_service.ListWorkLogs(userInfo.UserID, &req, middleware.IsCacheBypassed(c))
+	if work_log_service.IsListQueryError(err) {
+		return render.BadRequest(c, err.Error())
+	}
 	if err != nil {
 		return render.Error(c, fiber.StatusInternalServerError, "internal error")
 	}
 
-	responses := make([]contract.WorkLogResponse, len(logs))
-	for i, log := range logs {
-		responses[i] = toWorkLogResponse(&log)
+	responses := make([]contract.WorkLogResponse, len(page.Logs))
+	for i, log := range page.Logs {
+		responses[i] = ToWorkLogResponse(&log)
 	}
 
 	return render.JSON(c, fiber.StatusOK, contract.WorkLogListResponse{
+		Data:       responses,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	})
+}
+
+// SearchWorkLogs handles GET /work-logs/search
+func SearchWorkLogs(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.SearchWorkLogsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+
+	results, total, err := work_log_search_service.SearchWorkLogs(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	responses := make([]contract.WorkLogSearchResultResponse, len(results))
+	for i, result := range results {
+		responses[i] = contract.WorkLogSearchResultResponse{
+			ID:      result.ID,
+			Date:    result.Date,
+			Snippet: result.Snippet,
+			Rank:    result.Rank,
+		}
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogSearchResponse{
+		Data:  responses,
+		Total: total,
+	})
+}
+
+// ListWorkLogTags handles GET /work-logs/tags
+func ListWorkLogTags(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.ListWorkLogTagsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+
+	counts, err := work_log_tag_service.ListTagCounts(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	responses := make([]contract.WorkLogTagCountResponse, len(counts))
+	for i, count := range counts {
+		responses[i] = contract.WorkLogTagCountResponse{
+			Kind:        count.Kind,
+			Name:        count.Name,
+			Days:        count.Days,
+			Occurrences: count.Occurrences,
+			LastUsedOn:  count.LastUsedOn,
+		}
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogTagListResponse{
 		Data: responses,
 	})
 }
 
+// GetWorkLogStats handles GET /work-logs/stats
+func GetWorkLogStats(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.WorkLogStatsRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+
+	stats, err := work_log_stats_service.GetStats(userInfo.UserID, req.Year)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	resp := contract.WorkLogStatsResponse{
+		Year:          stats.Year,
+		LoggedDays:    len(stats.Days),
+		CurrentStreak: stats.CurrentStreak,
+		LongestStreak: stats.LongestStreak,
+		Heatmap:       make([]contract.WorkLogDayStatResponse, len(stats.Days)),
+		Months:        make([]contract.WorkLogMonthStatResponse, len(stats.Months)),
+		TopTags:       make([]contract.WorkLogTagCountResponse, len(stats.TopTags)),
+	}
+	for i, day := range stats.Days {
+		resp.Heatmap[i] = contract.WorkLogDayStatResponse{
+			Date:  day.Date,
+			Chars: day.Chars,
+			Items: day.Items,
+			Level: day.Level,
+		}
+	}
+	for i, month := range stats.Months {
+		resp.Months[i] = contract.WorkLogMonthStatResponse{
+			Month: month.Month,
+			Days:  month.Days,
+			Chars: month.Chars,
+		}
+	}
+	for i, count := range stats.TopTags {
+		resp.TopTags[i] = contract.WorkLogTagCountResponse{
+			Kind:        count.Kind,
+			Name:        count.Name,
+			Days:        count.Days,
+			Occurrences: count.Occurrences,
+			LastUsedOn:  count.LastUsedOn,
+		}
+	}
+
+	return render.JSON(c, fiber.StatusOK, resp)
+}
+
 // DeleteWorkLogByDate handles DELETE /work-logs/:date
 func DeleteWorkLogByDate(c *fiber.Ctx) error {
 	userInfo := middleware.GetUserFromContext(c)
@@ -117,21 +437,27 @@ func DownloadWorkLogs(c *fiber.Ctx) error {
 
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
+	projectID, err := strconv.ParseInt(c.Query("project_id", "0"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid project_id")
+	}
 
 	req := &work_log_download_service.DownloadRequest{
 		StartDate: startDate,
 		EndDate:   endDate,
+		ProjectID: projectID,
+		Format:    c.Query("format"),
 	}
 
-	markdown, filename, err := work_log_download_service.DownloadWorkLogs(userInfo.UserID, req)
+	download, err := work_log_download_service.DownloadWorkLogs(userInfo.UserID, req)
 	if err != nil {
 		return render.BadRequest(c, err.Error())
 	}
 
-	c.Set("Content-Type", "text/markdown; charset=utf-8")
-	c.Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Set("Content-Type", download.ContentType)
+	c.Set("Content-Disposition", "attachment; filename=\""+download.Filename+"\"")
 
-	return c.SendString(markdown)
+	return c.SendString(download.Content)
 }
 
 // ImportWorkLogs handles POST /work-logs/import
@@ -161,6 +487,15 @@ func ImportWorkLogs(c *fiber.Ctx) error {
 		return render.Error(c, fiber.StatusInternalServerError, "failed to read file")
 	}
 
+	// Large files can be imported in the background and polled via GET /jobs/:id
+	if c.QueryBool("async") || c.FormValue("async") == "true" {
+		job, err := work_log_import_service.EnqueueImportFromMarkdown(userInfo.UserID, string(content))
+		if err != nil {
+			return render.BadRequest(c, err.Error())
+		}
+		return render.JSON(c, fiber.StatusAccepted, background_job_handler.ToBackgroundJobResponse(job))
+	}
+
 	// Import worklogs from markdown
 	result, err := work_log_import_service.ImportFromMarkdown(userInfo.UserID, string(content))
 	if err != nil {
@@ -0,0 +1,146 @@
+package work_log_revision_handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/handlers/work_log_handler"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/work_log_revision_service"
+	"worknote-api/services/work_log_service"
+	"worknote-api/utils/diff"
+	"worknote-api/utils/render"
+)
+
+// toRevisionResponse converts a model to response
+func toRevisionResponse(revision *model.WorkLogRevision) contract.WorkLogRevisionResponse {
+	date := revision.Date
+	if len(date) > 10 {
+		date = date[:10]
+	}
+	return contract.WorkLogRevisionResponse{
+		ID:        revision.ID,
+		Date:      date,
+		Content:   revision.Content,
+		Reason:    revision.Reason,
+		CreatedAt: revision.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// ListRevisions handles GET /work-logs/:date/revisions
+func ListRevisions(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	revisions, err := work_log_revision_service.ListRevisions(userInfo.UserID, c.Params("date"), limit, offset)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	responses := make([]contract.WorkLogRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = toRevisionResponse(&revision)
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogRevisionListResponse{
+		Data: responses,
+	})
+}
+
+// GetRevision handles GET /work-logs/:date/revisions/:id
+func GetRevision(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	revision, err := work_log_revision_service.GetRevision(userInfo.UserID, c.Params("date"), id)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if revision == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toRevisionResponse(revision))
+}
+
+// DiffRevisions handles GET /work-logs/:date/revisions/diff?from=&to=
+// Without to, the revision is compared against the current content.
+func DiffRevisions(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	fromID, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "from must be a revision id")
+	}
+	toID, err := strconv.ParseInt(c.Query("to", "0"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "to must be a revision id")
+	}
+
+	lines, err := work_log_revision_service.DiffRevisions(userInfo.UserID, c.Params("date"), fromID, toID)
+	if err == diff.ErrTooLarge {
+		return render.Error(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if lines == nil {
+		return render.Error(c, fiber.StatusNotFound, "revision not found")
+	}
+
+	responses := make([]contract.DiffLineResponse, len(lines))
+	for i, line := range lines {
+		responses[i] = contract.DiffLineResponse{Op: line.Op, Text: line.Text}
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogRevisionDiffResponse{
+		From:    fromID,
+		To:      toID,
+		Lines:   responses,
+		Unified: diff.Unified(lines),
+	})
+}
+
+// RestoreRevision handles POST /work-logs/:date/revisions/:id/restore
+func RestoreRevision(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	workLog, err := work_log_revision_service.RestoreRevision(userInfo.UserID, c.Params("date"), id)
+	if err == work_log_service.ErrWorkLogTrashed {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if workLog == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, work_log_handler.ToWorkLogResponse(workLog))
+}
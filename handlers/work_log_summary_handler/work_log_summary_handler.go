@@ -1,26 +1,71 @@
 package work_log_summary_handler
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
+	log "github.com/sirupsen/logrus"
 
 	"worknote-api/contract"
+	"worknote-api/handlers/background_job_handler"
 	"worknote-api/middleware"
 	"worknote-api/model"
 	"worknote-api/services/work_log_summary_service"
+	"worknote-api/utils/period"
 	"worknote-api/utils/render"
 )
 
 // toSummaryResponse converts a model to response
 func toSummaryResponse(summary *model.WorkLogSummary) contract.WorkLogSummaryResponse {
 	return contract.WorkLogSummaryResponse{
-		ID:        summary.ID,
-		Month:     summary.Month,
-		Summary:   summary.Summary,
-		CreatedAt: summary.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: summary.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:          summary.ID,
+		ProjectID:   summary.ProjectID,
+		Month:       work_log_summary_service.MonthOf(summary),
+		PeriodType:  summary.PeriodType,
+		Period:      work_log_summary_service.PeriodOf(summary).Label(),
+		PeriodStart: summary.PeriodStart,
+		PeriodEnd:   summary.PeriodEnd,
+		Summary:     summary.Summary,
+		Version:     summary.Version,
+		Pinned:      summary.Pinned,
+		Strategy:    summary.Strategy,
+		Template:    summary.Template,
+		CreatedAt:   summary.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   summary.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// toVersionResponse converts a model to response
+func toVersionResponse(version *model.WorkLogSummaryVersion, current int) contract.WorkLogSummaryVersionResponse {
+	return contract.WorkLogSummaryVersionResponse{
+		Version:          version.Version,
+		Summary:          version.Summary,
+		Source:           version.Source,
+		Strategy:         version.Strategy,
+		Template:         version.Template,
+		TemplateID:       version.TemplateID,
+		TemplateVersion:  version.TemplateVersion,
+		Provider:         version.Provider,
+		Model:            version.Model,
+		PromptHash:       version.PromptHash,
+		PromptTokens:     version.PromptTokens,
+		CompletionTokens: version.CompletionTokens,
+		Current:          version.Version == current,
+		CreatedAt:        version.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
 
+// summaryOptions builds the template selection of a summary request
+func summaryOptions(template string, templateID int64) work_log_summary_service.Options {
+	return work_log_summary_service.Options{Template: template, TemplateID: templateID}
+}
+
 // GenerateSummary handles POST /work-logs/summary
 func GenerateSummary(c *fiber.Ctx) error {
 	userInfo := middleware.GetUserFromContext(c)
@@ -33,18 +78,101 @@ func GenerateSummary(c *fiber.Ctx) error {
 		return render.BadRequest(c, "invalid request body")
 	}
 
+	// Without a month, summarize the current one in the user's timezone
 	if req.Month == "" {
-		return render.BadRequest(c, "month is required")
+		month, err := work_log_summary_service.CurrentMonth(userInfo.UserID)
+		if err != nil {
+			return render.Error(c, fiber.StatusInternalServerError, "internal error")
+		}
+		req.Month = month
 	}
 
-	summary, err := work_log_summary_service.GenerateSummary(userInfo.UserID, req.Month)
+	if req.Async {
+		job, err := work_log_summary_service.EnqueueGenerateSummary(userInfo.UserID, req.Month, req.ProjectID, summaryOptions(req.Template, req.TemplateID))
+		if err != nil {
+			return render.BadRequest(c, err.Error())
+		}
+		return render.JSON(c, fiber.StatusAccepted, background_job_handler.ToBackgroundJobResponse(job))
+	}
+
+	// Summaries too long for a single prompt are queued whatever async says
+	summary, job, err := work_log_summary_service.GenerateSummary(userInfo.UserID, req.Month, req.ProjectID, summaryOptions(req.Template, req.TemplateID))
 	if err != nil {
 		return render.BadRequest(c, err.Error())
 	}
+	if job != nil {
+		return render.JSON(c, fiber.StatusAccepted, background_job_handler.ToBackgroundJobResponse(job))
+	}
 
 	return render.JSON(c, fiber.StatusOK, toSummaryResponse(summary))
 }
 
+// StreamSummary handles POST /work-logs/summary/stream, relaying the summary as server-sent
+// token events followed by a done event with the saved summary, or an error event.
+// A summary that has to be queued is answered with its background job instead.
+func StreamSummary(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.GenerateSummaryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	// Without a month, summarize the current one in the user's timezone
+	if req.Month == "" {
+		month, err := work_log_summary_service.CurrentMonth(userInfo.UserID)
+		if err != nil {
+			return render.Error(c, fiber.StatusInternalServerError, "internal error")
+		}
+		req.Month = month
+	}
+
+	stream, job, err := work_log_summary_service.NewSummaryStream(userInfo.UserID, req.Month, req.ProjectID, summaryOptions(req.Template, req.TemplateID))
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	// Summaries too long for a single prompt cannot be streamed and are queued instead
+	if job != nil {
+		return render.JSON(c, fiber.StatusAccepted, background_job_handler.ToBackgroundJobResponse(job))
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// A failed flush means the client went away, which aborts the generation
+		writeEvent := func(event string, data interface{}) error {
+			payload, err := json.Marshal(data)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+			return w.Flush()
+		}
+
+		summary, err := stream.Run(ctx, func(token string) error {
+			return writeEvent("token", contract.SummaryStreamTokenData{Content: token})
+		})
+		if err != nil {
+			log.Debugf("summary stream ended for user %d: %v", userInfo.UserID, err)
+			writeEvent("error", fiber.Map{"error": err.Error()})
+			return
+		}
+
+		writeEvent("done", toSummaryResponse(summary))
+	})
+
+	return nil
+}
+
 // GetSummary handles GET /work-logs/summary/:month
 func GetSummary(c *fiber.Ctx) error {
 	userInfo := middleware.GetUserFromContext(c)
@@ -57,7 +185,12 @@ func GetSummary(c *fiber.Ctx) error {
 		return render.BadRequest(c, "month is required")
 	}
 
-	summary, err := work_log_summary_service.GetSummary(userInfo.UserID, month)
+	projectID, err := strconv.ParseInt(c.Query("project_id", "0"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid project_id")
+	}
+
+	summary, err := work_log_summary_service.GetSummary(userInfo.UserID, month, projectID, middleware.IsCacheBypassed(c))
 	if err != nil {
 		return render.BadRequest(c, err.Error())
 	}
@@ -67,3 +200,257 @@ func GetSummary(c *fiber.Ctx) error {
 
 	return render.JSON(c, fiber.StatusOK, toSummaryResponse(summary))
 }
+
+// CreateSummary handles POST /summaries
+func CreateSummary(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CreateSummaryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	p, err := work_log_summary_service.ResolvePeriod(userInfo.UserID, req.PeriodType, req.Period, req.Start, req.End)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	if req.Async {
+		job, err := work_log_summary_service.EnqueueGeneratePeriodSummary(userInfo.UserID, p, req.ProjectID, summaryOptions(req.Template, req.TemplateID))
+		if err != nil {
+			return render.BadRequest(c, err.Error())
+		}
+		return render.JSON(c, fiber.StatusAccepted, background_job_handler.ToBackgroundJobResponse(job))
+	}
+
+	// Summaries too long for a single prompt are queued whatever async says
+	summary, job, err := work_log_summary_service.GeneratePeriodSummary(userInfo.UserID, p, req.ProjectID, summaryOptions(req.Template, req.TemplateID))
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if job != nil {
+		return render.JSON(c, fiber.StatusAccepted, background_job_handler.ToBackgroundJobResponse(job))
+	}
+
+	return render.JSON(c, fiber.StatusOK, toSummaryResponse(summary))
+}
+
+// ListSummaries handles GET /summaries?period_type=
+func ListSummaries(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.ListSummariesRequest
+	if err := c.QueryParser(&req); err != nil {
+		return render.BadRequest(c, "invalid query parameters")
+	}
+	if req.PeriodType == "" {
+		req.PeriodType = "month"
+	}
+
+	summaries, total, err := work_log_summary_service.ListSummaries(userInfo.UserID, req.PeriodType, req.ProjectID, req.Limit, req.Offset)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	responses := make([]contract.WorkLogSummaryResponse, len(summaries))
+	for i, summary := range summaries {
+		responses[i] = toSummaryResponse(&summary)
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogSummaryListResponse{
+		Data:  responses,
+		Total: total,
+	})
+}
+
+// GetPeriodSummary handles GET /summaries/:period, the period being a label such as
+// 2026-W42, 2026-10, 2026-Q4, 2026 or 2026-10-01..2026-10-15
+func GetPeriodSummary(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	projectID, err := strconv.ParseInt(c.Query("project_id", "0"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid project_id")
+	}
+
+	p, err := work_log_summary_service.ResolvePeriod(userInfo.UserID, "", c.Params("period"), "", "")
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	summary, err := work_log_summary_service.GetPeriodSummary(userInfo.UserID, p, projectID, middleware.IsCacheBypassed(c))
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if summary == nil {
+		return render.Error(c, fiber.StatusNotFound, "summary not found for the specified period")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toSummaryResponse(summary))
+}
+
+// parsePeriodParams reads the :period label and the project_id query shared by the summary version routes
+func parsePeriodParams(c *fiber.Ctx, userID int64) (period.Period, int64, error) {
+	projectID, err := strconv.ParseInt(c.Query("project_id", "0"), 10, 64)
+	if err != nil {
+		return period.Period{}, 0, errors.New("invalid project_id")
+	}
+
+	p, err := work_log_summary_service.ResolvePeriod(userID, "", c.Params("period"), "", "")
+	if err != nil {
+		return period.Period{}, 0, err
+	}
+	return p, projectID, nil
+}
+
+// renderVersionError maps the summary version errors to responses
+func renderVersionError(c *fiber.Ctx, err error) error {
+	if err == work_log_summary_service.ErrSummaryNotFound || err == work_log_summary_service.ErrVersionNotFound {
+		return render.Error(c, fiber.StatusNotFound, err.Error())
+	}
+	return render.Error(c, fiber.StatusInternalServerError, "internal error")
+}
+
+// ListSummaryVersions handles GET /summaries/:period/versions
+func ListSummaryVersions(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	p, projectID, err := parsePeriodParams(c, userInfo.UserID)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	summary, versions, err := work_log_summary_service.ListSummaryVersions(userInfo.UserID, p, projectID)
+	if err != nil {
+		return renderVersionError(c, err)
+	}
+
+	responses := make([]contract.WorkLogSummaryVersionResponse, len(versions))
+	for i, version := range versions {
+		responses[i] = toVersionResponse(&version, summary.Version)
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogSummaryVersionListResponse{Data: responses})
+}
+
+// EditSummary handles POST /summaries/:period/versions, saving a hand-edited text as a pinned version
+func EditSummary(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	p, projectID, err := parsePeriodParams(c, userInfo.UserID)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	var req contract.EditSummaryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+	if strings.TrimSpace(req.Summary) == "" {
+		return render.BadRequest(c, "summary is required")
+	}
+
+	summary, err := work_log_summary_service.EditSummary(userInfo.UserID, p, projectID, req.Summary)
+	if err != nil {
+		return renderVersionError(c, err)
+	}
+
+	return render.JSON(c, fiber.StatusCreated, toSummaryResponse(summary))
+}
+
+// PinSummaryVersion handles PUT /summaries/:period/pin
+func PinSummaryVersion(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	p, projectID, err := parsePeriodParams(c, userInfo.UserID)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	var req contract.PinSummaryVersionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+	if req.Version <= 0 {
+		return render.BadRequest(c, "version is required")
+	}
+
+	summary, err := work_log_summary_service.PinSummaryVersion(userInfo.UserID, p, projectID, req.Version)
+	if err != nil {
+		return renderVersionError(c, err)
+	}
+
+	return render.JSON(c, fiber.StatusOK, toSummaryResponse(summary))
+}
+
+// UnpinSummary handles DELETE /summaries/:period/pin
+func UnpinSummary(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	p, projectID, err := parsePeriodParams(c, userInfo.UserID)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	summary, err := work_log_summary_service.UnpinSummary(userInfo.UserID, p, projectID)
+	if err != nil {
+		return renderVersionError(c, err)
+	}
+
+	return render.JSON(c, fiber.StatusOK, toSummaryResponse(summary))
+}
+
+// GetAutoSummary handles GET /me/auto-summary
+func GetAutoSummary(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	enabled, err := work_log_summary_service.GetAutoSummary(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.AutoSummaryResponse{Enabled: enabled})
+}
+
+// UpdateAutoSummary handles PUT /me/auto-summary
+func UpdateAutoSummary(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.AutoSummaryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	if err := work_log_summary_service.SetAutoSummary(userInfo.UserID, req.Enabled); err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.AutoSummaryResponse{Enabled: req.Enabled})
+}
@@ -0,0 +1,188 @@
+package work_log_template_handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"worknote-api/contract"
+	"worknote-api/handlers/work_log_handler"
+	"worknote-api/middleware"
+	"worknote-api/model"
+	"worknote-api/services/work_log_service"
+	"worknote-api/services/work_log_template_service"
+	"worknote-api/utils/render"
+)
+
+// toWorkLogTemplateResponse converts a model to response
+func toWorkLogTemplateResponse(template *model.WorkLogTemplate) contract.WorkLogTemplateResponse {
+	return contract.WorkLogTemplateResponse{
+		ID:        template.ID,
+		Name:      template.Name,
+		Content:   template.Content,
+		IsDefault: template.IsDefault,
+		CreatedAt: template.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: template.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// CreateTemplate handles POST /me/templates
+func CreateTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	var req contract.CreateWorkLogTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	template, err := work_log_template_service.CreateTemplate(userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusCreated, toWorkLogTemplateResponse(template))
+}
+
+// ListTemplates handles GET /me/templates
+func ListTemplates(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	templates, err := work_log_template_service.ListTemplates(userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	responses := make([]contract.WorkLogTemplateResponse, len(templates))
+	for i, template := range templates {
+		responses[i] = toWorkLogTemplateResponse(&template)
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogTemplateListResponse{
+		Data: responses,
+	})
+}
+
+// GetTemplate handles GET /me/templates/:id
+func GetTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	template, err := work_log_template_service.GetTemplate(id, userInfo.UserID)
+	if err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+	if template == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toWorkLogTemplateResponse(template))
+}
+
+// UpdateTemplate handles PUT /me/templates/:id
+func UpdateTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	var req contract.UpdateWorkLogTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return render.BadRequest(c, "invalid request body")
+	}
+
+	template, err := work_log_template_service.UpdateTemplate(id, userInfo.UserID, &req)
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+	if template == nil {
+		return render.Error(c, fiber.StatusNotFound, "not found")
+	}
+
+	return render.JSON(c, fiber.StatusOK, toWorkLogTemplateResponse(template))
+}
+
+// DeleteTemplate handles DELETE /me/templates/:id
+func DeleteTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	if err := work_log_template_service.DeleteTemplate(id, userInfo.UserID); err != nil {
+		return render.Error(c, fiber.StatusInternalServerError, "internal error")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// PreviewTemplate handles GET /me/templates/:id/preview?date=
+func PreviewTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return render.BadRequest(c, "invalid id")
+	}
+
+	date, content, err := work_log_template_service.PreviewTemplate(id, userInfo.UserID, c.Query("date"))
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusOK, contract.WorkLogTemplatePreviewResponse{
+		Date:    date,
+		Content: content,
+	})
+}
+
+// CreateWorkLogFromTemplate handles POST /work-logs/:date/from-template
+func CreateWorkLogFromTemplate(c *fiber.Ctx) error {
+	userInfo := middleware.GetUserFromContext(c)
+	if userInfo == nil {
+		return render.Unauthorized(c, "unauthorized")
+	}
+
+	// The body is optional, without it the default template is used
+	var req contract.CreateWorkLogFromTemplateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return render.BadRequest(c, "invalid request body")
+		}
+	}
+
+	workLog, err := work_log_template_service.CreateWorkLogFromTemplate(userInfo.UserID, c.Params("date"), req.TemplateID)
+	if err == work_log_template_service.ErrWorkLogExists || err == work_log_service.ErrWorkLogTrashed {
+		return render.Error(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		return render.BadRequest(c, err.Error())
+	}
+
+	return render.JSON(c, fiber.StatusCreated, work_log_handler.ToWorkLogResponse(workLog))
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -10,15 +13,46 @@ import (
 	"worknote-api/config"
 	"worknote-api/datastore"
 	"worknote-api/handlers/auth_handler"
+	"worknote-api/handlers/background_job_handler"
+	"worknote-api/handlers/calendar_handler"
+	"worknote-api/handlers/event_handler"
 	"worknote-api/handlers/job_application_handler"
+	"worknote-api/handlers/project_handler"
+	"worknote-api/handlers/report_handler"
+	"worknote-api/handlers/summary_template_handler"
+	"worknote-api/handlers/timer_handler"
+	"worknote-api/handlers/trash_handler"
+	"worknote-api/handlers/user_settings_handler"
+	"worknote-api/handlers/webhook_handler"
 	"worknote-api/handlers/work_log_handler"
+	"worknote-api/handlers/work_log_revision_handler"
 	"worknote-api/handlers/work_log_summary_handler"
+	"worknote-api/handlers/work_log_template_handler"
 	"worknote-api/middleware"
+	"worknote-api/repos/background_job_repo"
+	"worknote-api/repos/calendar_repo"
 	"worknote-api/repos/job_application_log_repo"
 	"worknote-api/repos/job_application_repo"
+	"worknote-api/repos/project_repo"
+	"worknote-api/repos/summary_template_repo"
+	"worknote-api/repos/timer_repo"
 	"worknote-api/repos/user_repo"
+	"worknote-api/repos/user_settings_repo"
+	"worknote-api/repos/webhook_repo"
+	"worknote-api/repos/work_log_item_repo"
 	"worknote-api/repos/work_log_repo"
+	"worknote-api/repos/work_log_revision_repo"
 	"worknote-api/repos/work_log_summary_repo"
+	"worknote-api/repos/work_log_tag_repo"
+	"worknote-api/repos/work_log_template_repo"
+	"worknote-api/services/background_job_service"
+	"worknote-api/services/event_service"
+	"worknote-api/services/realtime_service"
+	"worknote-api/services/scheduler_service"
+	"worknote-api/services/trash_service"
+	"worknote-api/services/webhook_service"
+	"worknote-api/services/work_log_import_service"
+	"worknote-api/services/work_log_summary_service"
 	"worknote-api/utils/render"
 )
 
@@ -36,6 +70,49 @@ func main() {
 	job_application_log_repo.Initialize()
 	work_log_repo.Initialize()
 	work_log_summary_repo.Initialize()
+	work_log_tag_repo.Initialize()
+	background_job_repo.Initialize()
+	webhook_repo.Initialize()
+	project_repo.Initialize()
+	work_log_item_repo.Initialize()
+	timer_repo.Initialize()
+	work_log_revision_repo.Initialize()
+	work_log_template_repo.Initialize()
+	summary_template_repo.Initialize()
+	calendar_repo.Initialize()
+	user_settings_repo.Initialize()
+
+	// Fan domain events out to webhook subscriptions and open event streams
+	event_service.Subscribe(webhook_service.HandleEvent)
+	event_service.Subscribe(realtime_service.HandleEvent)
+
+	// Register background job handlers and start workers
+	background_job_service.Register(work_log_summary_service.JobTypeGenerateSummary, work_log_summary_service.HandleGenerateSummaryJob, background_job_service.Options{
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+	})
+	background_job_service.Register(work_log_import_service.JobTypeImport, work_log_import_service.HandleImportJob, background_job_service.Options{
+		MaxAttempts: 1,
+	})
+	background_job_service.Register(webhook_service.JobTypeDeliver, webhook_service.HandleDeliveryJob, background_job_service.Options{
+		MaxAttempts: 6,
+		Backoff:     30 * time.Second,
+	})
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	background_job_service.StartWorkers(workerCtx, config.Get().JobWorkers)
+
+	// Register scheduled tasks and start the scheduler
+	if config.Get().SchedulerEnabled {
+		if err := scheduler_service.Register("auto_monthly_summary", config.Get().AutoSummaryCron, work_log_summary_service.RunAutoSummaries); err != nil {
+			log.Fatalf("failed to register auto summary schedule: %v", err)
+		}
+		if err := scheduler_service.Register("trash_purge", config.Get().TrashPurgeCron, trash_service.RunPurge); err != nil {
+			log.Fatalf("failed to register trash purge schedule: %v", err)
+		}
+		scheduler_service.Start(workerCtx)
+	}
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -54,6 +131,47 @@ func main() {
 
 	// Protected routes
 	app.Get("/me", middleware.AuthMiddleware, meHandler)
+	app.Get("/me/auto-summary", middleware.AuthMiddleware, work_log_summary_handler.GetAutoSummary)
+	app.Put("/me/auto-summary", middleware.AuthMiddleware, work_log_summary_handler.UpdateAutoSummary)
+	app.Get("/me/settings", middleware.AuthMiddleware, user_settings_handler.GetSettings)
+	app.Patch("/me/settings", middleware.AuthMiddleware, user_settings_handler.UpdateSettings)
+
+	// Webhook routes (protected)
+	webhooks := app.Group("/me/webhooks", middleware.AuthMiddleware)
+	webhooks.Post("/", webhook_handler.CreateWebhook)
+	webhooks.Get("/", webhook_handler.ListWebhooks)
+	webhooks.Get("/:id", webhook_handler.GetWebhook)
+	webhooks.Put("/:id", webhook_handler.UpdateWebhook)
+	webhooks.Delete("/:id", webhook_handler.DeleteWebhook)
+	webhooks.Get("/:id/deliveries", webhook_handler.ListWebhookDeliveries)
+	webhooks.Post("/:id/deliveries/:delivery_id/redeliver", webhook_handler.RedeliverWebhookDelivery)
+
+	// Daily log template routes (protected)
+	templates := app.Group("/me/templates", middleware.AuthMiddleware)
+	templates.Post("/", work_log_template_handler.CreateTemplate)
+	templates.Get("/", work_log_template_handler.ListTemplates)
+	templates.Get("/:id", work_log_template_handler.GetTemplate)
+	templates.Put("/:id", work_log_template_handler.UpdateTemplate)
+	templates.Delete("/:id", work_log_template_handler.DeleteTemplate)
+	templates.Get("/:id/preview", work_log_template_handler.PreviewTemplate)
+
+	// Summary prompt template routes (protected)
+	summaryTemplates := app.Group("/me/summary-templates", middleware.AuthMiddleware)
+	summaryTemplates.Post("/", summary_template_handler.CreateSummaryTemplate)
+	summaryTemplates.Get("/", summary_template_handler.ListSummaryTemplates)
+	summaryTemplates.Get("/:id", summary_template_handler.GetSummaryTemplate)
+	summaryTemplates.Put("/:id", summary_template_handler.UpdateSummaryTemplate)
+	summaryTemplates.Delete("/:id", summary_template_handler.DeleteSummaryTemplate)
+	summaryTemplates.Get("/:id/versions", summary_template_handler.ListSummaryTemplateVersions)
+
+	// Working calendar routes (protected)
+	calendar := app.Group("/me/calendar", middleware.AuthMiddleware)
+	calendar.Get("/workdays", calendar_handler.GetWorkdays)
+	calendar.Put("/workdays", calendar_handler.UpdateWorkdays)
+	calendar.Get("/days", calendar_handler.ListDays)
+	calendar.Post("/days", calendar_handler.CreateDay)
+	calendar.Delete("/days/:id", calendar_handler.DeleteDay)
+	calendar.Post("/holidays/import", calendar_handler.ImportHolidays)
 
 	// Job Application routes (protected)
 	jobApps := app.Group("/job-applications", middleware.AuthMiddleware)
@@ -70,16 +188,77 @@ func main() {
 	jobApps.Put("/:id/logs/:log_id", job_application_handler.UpdateJobApplicationLog)
 	jobApps.Delete("/:id/logs/:log_id", job_application_handler.DeleteJobApplicationLog)
 
+	// Project routes (protected)
+	projects := app.Group("/projects", middleware.AuthMiddleware)
+	projects.Post("/", project_handler.CreateProject)
+	projects.Get("/", project_handler.ListProjects)
+	projects.Get("/:id", project_handler.GetProject)
+	projects.Put("/:id", project_handler.UpdateProject)
+	projects.Delete("/:id", project_handler.DeleteProject)
+	projects.Get("/:id/timeline", project_handler.GetProjectTimeline)
+
 	// Work Log routes (protected)
 	workLogs := app.Group("/work-logs", middleware.AuthMiddleware)
 	workLogs.Put("/", work_log_handler.UpsertWorkLog)
 	workLogs.Get("/", work_log_handler.ListWorkLogs)
 	workLogs.Get("/download", work_log_handler.DownloadWorkLogs)
+	workLogs.Get("/search", work_log_handler.SearchWorkLogs)
+	workLogs.Get("/tags", work_log_handler.ListWorkLogTags)
+	workLogs.Get("/stats", work_log_handler.GetWorkLogStats)
+	workLogs.Get("/gaps", calendar_handler.GetWorkLogGaps)
 	workLogs.Post("/import", work_log_handler.ImportWorkLogs)
 	workLogs.Post("/summary", work_log_summary_handler.GenerateSummary)
+	workLogs.Post("/summary/stream", work_log_summary_handler.StreamSummary)
 	workLogs.Get("/summary/:month", work_log_summary_handler.GetSummary)
+	workLogs.Post("/today/items", work_log_handler.AppendTodayWorkLogItem)
 	workLogs.Get("/:date", work_log_handler.GetWorkLogByDate)
 	workLogs.Delete("/:date", work_log_handler.DeleteWorkLogByDate)
+	workLogs.Get("/:date/items", work_log_handler.ListWorkLogItems)
+	workLogs.Post("/:date/items", work_log_handler.CreateWorkLogItem)
+	workLogs.Put("/:date/items/reorder", work_log_handler.ReorderWorkLogItems)
+	workLogs.Put("/:date/items/:item_id", work_log_handler.UpdateWorkLogItem)
+	workLogs.Delete("/:date/items/:item_id", work_log_handler.DeleteWorkLogItem)
+	workLogs.Post("/:date/from-template", work_log_template_handler.CreateWorkLogFromTemplate)
+	workLogs.Get("/:date/revisions", work_log_revision_handler.ListRevisions)
+	workLogs.Get("/:date/revisions/diff", work_log_revision_handler.DiffRevisions)
+	workLogs.Get("/:date/revisions/:id", work_log_revision_handler.GetRevision)
+	workLogs.Post("/:date/revisions/:id/restore", work_log_revision_handler.RestoreRevision)
+
+	// Summary routes (protected)
+	summaries := app.Group("/summaries", middleware.AuthMiddleware)
+	summaries.Post("/", work_log_summary_handler.CreateSummary)
+	summaries.Get("/", work_log_summary_handler.ListSummaries)
+	summaries.Get("/:period/versions", work_log_summary_handler.ListSummaryVersions)
+	summaries.Post("/:period/versions", work_log_summary_handler.EditSummary)
+	summaries.Put("/:period/pin", work_log_summary_handler.PinSummaryVersion)
+	summaries.Delete("/:period/pin", work_log_summary_handler.UnpinSummary)
+	summaries.Get("/:period", work_log_summary_handler.GetPeriodSummary)
+
+	// Trash routes (protected)
+	trash := app.Group("/trash", middleware.AuthMiddleware)
+	trash.Get("/", trash_handler.ListTrash)
+	trash.Post("/work-logs/:date/restore", trash_handler.RestoreWorkLog)
+	trash.Delete("/work-logs/:date", trash_handler.PurgeWorkLog)
+	trash.Post("/job-applications/:id/restore", trash_handler.RestoreJobApplication)
+	trash.Delete("/job-applications/:id", trash_handler.PurgeJobApplication)
+
+	// Timer routes (protected)
+	timers := app.Group("/timers", middleware.AuthMiddleware)
+	timers.Post("/start", timer_handler.StartTimer)
+	timers.Post("/stop", timer_handler.StopTimer)
+	timers.Get("/current", timer_handler.GetTimer)
+
+	// Report routes (protected)
+	reports := app.Group("/reports", middleware.AuthMiddleware)
+	reports.Get("/timesheet", report_handler.GetTimesheet)
+	reports.Get("/standup", report_handler.GetStandup)
+
+	// Real-time event routes (protected)
+	app.Post("/events/ticket", middleware.AuthMiddleware, event_handler.CreateTicket)
+	app.Get("/events/stream", middleware.StreamAuthMiddleware, event_handler.Stream)
+
+	// Background job routes (protected)
+	app.Get("/jobs/:id", middleware.AuthMiddleware, background_job_handler.GetJob)
 
 	// Start server
 	cfg := config.Get()
@@ -7,6 +7,7 @@ import (
 
 	"worknote-api/contract"
 	"worknote-api/services/auth_service"
+	"worknote-api/services/realtime_service"
 	"worknote-api/utils/render"
 )
 
@@ -47,6 +48,24 @@ func AuthMiddleware(c *fiber.Ctx) error {
 	return c.Next()
 }
 
+// StreamAuthMiddleware authenticates event streams either with a short-lived
+// ?ticket= (for EventSource, which cannot set headers) or a regular bearer token
+func StreamAuthMiddleware(c *fiber.Ctx) error {
+	ticket := c.Query("ticket")
+	if ticket == "" {
+		return AuthMiddleware(c)
+	}
+
+	userID, err := realtime_service.RedeemTicket(ticket)
+	if err != nil {
+		return render.Unauthorized(c, "invalid or expired ticket")
+	}
+
+	c.Locals(UserInfoKey, &contract.UserInfo{UserID: userID})
+
+	return c.Next()
+}
+
 // GetUserFromContext retrieves user info from fiber context
 func GetUserFromContext(c *fiber.Ctx) *contract.UserInfo {
 	userInfo, ok := c.Locals(UserInfoKey).(*contract.UserInfo)
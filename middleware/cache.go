@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// CacheBypassHeader makes read endpoints skip the Redis cache, useful for debugging
+	CacheBypassHeader = "X-Cache-Bypass"
+)
+
+// IsCacheBypassed reports whether the request asked to skip cached reads,
+// either via X-Cache-Bypass or a standard Cache-Control: no-cache header
+func IsCacheBypassed(c *fiber.Ctx) bool {
+	switch strings.ToLower(c.Get(CacheBypassHeader)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return strings.Contains(strings.ToLower(c.Get(fiber.HeaderCacheControl)), "no-cache")
+}
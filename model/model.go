@@ -1,18 +1,23 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
 
 // User represents a user in the database
 type User struct {
-	ID         int64     `db:"id"`
-	Email      string    `db:"email"`
-	GoogleID   string    `db:"google_id"`
-	Username   string    `db:"username"`
-	Name       string    `db:"name"`
-	PictureURL string    `db:"picture_url"`
-	Role       string    `db:"role"`
-	CreatedAt  time.Time `db:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at"`
+	ID                 int64     `db:"id"`
+	Email              string    `db:"email"`
+	GoogleID           string    `db:"google_id"`
+	Username           string    `db:"username"`
+	Name               string    `db:"name"`
+	PictureURL         string    `db:"picture_url"`
+	Role               string    `db:"role"`
+	AutoSummaryEnabled bool      `db:"auto_summary_enabled"`
+	CreatedAt          time.Time `db:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at"`
 }
 
 // GoogleUserInfo represents the user info from Google OAuth
@@ -26,17 +31,18 @@ type GoogleUserInfo struct {
 
 // JobApplication represents a job application in the database
 type JobApplication struct {
-	ID          int64     `db:"id"`
-	UserID      int64     `db:"user_id"`
-	CompanyName string    `db:"company_name"`
-	JobTitle    string    `db:"job_title"`
-	JobURL      string    `db:"job_url"`
-	SalaryRange string    `db:"salary_range"`
-	Email       string    `db:"email"`
-	Notes       string    `db:"notes"`
-	State       string    `db:"state"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID          int64      `db:"id"`
+	UserID      int64      `db:"user_id"`
+	CompanyName string     `db:"company_name"`
+	JobTitle    string     `db:"job_title"`
+	JobURL      string     `db:"job_url"`
+	SalaryRange string     `db:"salary_range"`
+	Email       string     `db:"email"`
+	Notes       string     `db:"notes"`
+	State       string     `db:"state"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	DeletedAt   *time.Time `db:"deleted_at"`
 }
 
 // JobApplicationLog represents a log entry for a job application
@@ -52,20 +58,242 @@ type JobApplicationLog struct {
 
 // WorkLog represents a daily work log entry
 type WorkLog struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	Date      string     `db:"date"`
+	Content   string     `db:"content"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+// WorkLogSummary represents an AI-generated summary of a period
+type WorkLogSummary struct {
+	ID          int64     `db:"id"`
+	UserID      int64     `db:"user_id"`
+	ProjectID   int64     `db:"project_id"`
+	PeriodType  string    `db:"period_type"`  // week, month, quarter, year, custom
+	PeriodStart string    `db:"period_start"` // YYYY-MM-DD, inclusive
+	PeriodEnd   string    `db:"period_end"`   // YYYY-MM-DD, inclusive
+	Summary     string    `db:"summary"`      // Text of the current version
+	Version     int       `db:"version"`      // Current version number
+	Pinned      bool      `db:"pinned"`       // The current version is kept over newer ones
+	Strategy    string    `db:"strategy"`     // How the current version was generated: single, map_reduce
+	Template    string    `db:"template"`     // Prompt template of the current version, see WorkLogSummaryVersion
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+// WorkLogSummaryVersion is one generated or hand-edited text of a summary
+type WorkLogSummaryVersion struct {
+	ID               int64     `db:"id"`
+	SummaryID        int64     `db:"summary_id"`
+	Version          int       `db:"version"`
+	Summary          string    `db:"summary"`
+	Source           string    `db:"source"`   // generated, manual
+	Strategy         string    `db:"strategy"` // single, map_reduce, empty for manual edits
+	Template         string    `db:"template"` // Built-in style key or custom, empty for manual edits
+	TemplateID       int64     `db:"template_id"`
+	TemplateVersion  int       `db:"template_version"`
+	Provider         string    `db:"provider"`
+	Model            string    `db:"model"`
+	PromptHash       string    `db:"prompt_hash"` // SHA-256 of the prompt, empty for manual edits
+	PromptTokens     int       `db:"prompt_tokens"`
+	CompletionTokens int       `db:"completion_tokens"`
+	CreatedAt        time.Time `db:"created_at"`
+}
+
+// BackgroundJob represents a unit of asynchronous work processed by the job workers
+type BackgroundJob struct {
+	ID          int64      `db:"id"`
+	UserID      int64      `db:"user_id"`
+	Type        string     `db:"type"`
+	Payload     string     `db:"payload"`
+	Result      string     `db:"result"`
+	Status      string     `db:"status"`
+	Attempts    int        `db:"attempts"`
+	MaxAttempts int        `db:"max_attempts"`
+	Error       string     `db:"error"`
+	StartedAt   *time.Time `db:"started_at"`
+	FinishedAt  *time.Time `db:"finished_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
+
+// Webhook represents an outbound webhook subscription of a user
+type Webhook struct {
+	ID        int64          `db:"id"`
+	UserID    int64          `db:"user_id"`
+	URL       string         `db:"url"`
+	Secret    string         `db:"secret"`
+	Events    pq.StringArray `db:"events"`
+	Active    bool           `db:"active"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+// WebhookDelivery represents one event sent (or to be sent) to a webhook
+type WebhookDelivery struct {
+	ID             int64      `db:"id"`
+	WebhookID      int64      `db:"webhook_id"`
+	Event          string     `db:"event"`
+	Payload        string     `db:"payload"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	ResponseStatus int        `db:"response_status"`
+	Error          string     `db:"error"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+// WorkLogSearchResult is a work log matched by full-text search
+type WorkLogSearchResult struct {
+	WorkLog
+	Rank    float64 `db:"rank"`
+	Snippet string  `db:"snippet"`
+}
+
+// WorkLogTag is a #tag or @mention extracted from a work log
+type WorkLogTag struct {
+	ID          int64     `db:"id"`
+	WorkLogID   int64     `db:"work_log_id"`
+	UserID      int64     `db:"user_id"`
+	Kind        string    `db:"kind"`
+	Name        string    `db:"name"`
+	Occurrences int       `db:"occurrences"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// WorkLogTagCount aggregates the usage of a tag across work logs
+type WorkLogTagCount struct {
+	Kind        string `db:"kind"`
+	Name        string `db:"name"`
+	Days        int    `db:"days"`
+	Occurrences int    `db:"occurrences"`
+	LastUsedOn  string `db:"last_used_on"`
+}
+
+// Project groups work log bullets; a bullet belongs to a project when it carries the project's #tag
+type Project struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Name      string    `db:"name"`
+	Tag       string    `db:"tag"`
+	Color     string    `db:"color"`
+	Client    string    `db:"client"`
+	Archived  bool      `db:"archived"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// WorkLogItem is one bullet of a work log. The work log content is the rendered list of its items.
+type WorkLogItem struct {
+	ID              int64      `db:"id"`
+	WorkLogID       int64      `db:"work_log_id"`
+	UserID          int64      `db:"user_id"`
+	Position        int        `db:"position"`
+	Text            string     `db:"text"`
+	Status          string     `db:"status"`
+	ProjectID       int64      `db:"project_id"`       // zero when not attributed
+	DurationMinutes int        `db:"duration_minutes"` // zero when not tracked
+	StartedAt       *time.Time `db:"started_at"`
+	EndedAt         *time.Time `db:"ended_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+}
+
+// Timer is the running stopwatch of a user
+type Timer struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Text      string    `db:"text"`
+	ProjectID int64     `db:"project_id"`
+	StartedAt time.Time `db:"started_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// TimesheetRow is the tracked time of one group in a timesheet report
+type TimesheetRow struct {
+	Key     string `db:"key"`
+	Label   string `db:"label"`
+	Minutes int    `db:"minutes"`
+	Items   int    `db:"items"`
+}
+
+// WorkLogRevision is a prior version of a work log and the change that replaced it
+type WorkLogRevision struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Date      string    `db:"date"`
+	Content   string    `db:"content"`
+	Reason    string    `db:"reason"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// WorkLogDayStat is the size of one logged day
+type WorkLogDayStat struct {
+	Date  string `db:"date"`
+	Chars int    `db:"chars"`
+	Items int    `db:"items"`
+}
+
+// WorkLogMonthStat aggregates the logged days of a month
+type WorkLogMonthStat struct {
+	Month string `db:"month"`
+	Days  int    `db:"days"`
+	Chars int    `db:"chars"`
+}
+
+// CalendarDay is a public holiday or a personal leave day of a user
+type CalendarDay struct {
 	ID        int64     `db:"id"`
 	UserID    int64     `db:"user_id"`
 	Date      string    `db:"date"`
+	Kind      string    `db:"kind"` // holiday, leave
+	Name      string    `db:"name"`
+	Source    string    `db:"source"` // manual, ics
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// UserSettings holds the preferences of a user
+type UserSettings struct {
+	UserID       int64     `db:"user_id"`
+	Timezone     string    `db:"timezone"`
+	Locale       string    `db:"locale"`
+	WeekStart    string    `db:"week_start"`
+	ExportFormat string    `db:"export_format"`
+	AIProvider   string    `db:"ai_provider"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// WorkLogTemplate is a user-defined skeleton for a day's work log
+type WorkLogTemplate struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Name      string    `db:"name"`
 	Content   string    `db:"content"`
+	IsDefault bool      `db:"is_default"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
-// WorkLogSummary represents an AI-generated monthly summary
-type WorkLogSummary struct {
+// SummaryTemplate is a user-defined prompt for generating summaries
+type SummaryTemplate struct {
 	ID        int64     `db:"id"`
 	UserID    int64     `db:"user_id"`
-	Month     string    `db:"month"`
-	Summary   string    `db:"summary"`
+	Name      string    `db:"name"`
+	Content   string    `db:"content"`
+	Version   int       `db:"version"` // Bumped on every content change
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
+
+// SummaryTemplateVersion is the content a summary template had at one version
+type SummaryTemplateVersion struct {
+	TemplateID int64     `db:"template_id"`
+	Version    int       `db:"version"`
+	Content    string    `db:"content"`
+	CreatedAt  time.Time `db:"created_at"`
+}
@@ -0,0 +1,249 @@
+package background_job_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+const selectColumns = `
+	id, user_id, type, payload::text AS payload, COALESCE(result::text, '') AS result, status,
+	attempts, max_attempts, COALESCE(error, '') AS error, started_at, finished_at, created_at, updated_at
+`
+
+var (
+	stmtCreate        *sqlx.NamedStmt
+	stmtGetByID       *sqlx.Stmt
+	stmtGetByIDOfUser *sqlx.Stmt
+	stmtClaim         *sqlx.Stmt
+	stmtMarkSucceeded *sqlx.Stmt
+	stmtMarkRetry     *sqlx.Stmt
+	stmtMarkFailed    *sqlx.Stmt
+	stmtFailQueued    *sqlx.Stmt
+	stmtReapStale     *sqlx.Stmt
+	stmtTouchQueued   *sqlx.Stmt
+)
+
+// Initialize prepares all named statements for background job repository
+func Initialize() {
+	var err error
+
+	stmtCreate, err = datastore.DB.PrepareNamed(`
+		INSERT INTO background_jobs (user_id, type, payload, status, max_attempts)
+		VALUES (:user_id, :type, CAST(:payload AS JSONB), 'queued', :max_attempts)
+		RETURNING id, status, created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtCreate: %v", err)
+	}
+
+	stmtGetByID, err = datastore.DB.Preparex(`
+		SELECT ` + selectColumns + `
+		FROM background_jobs
+		WHERE id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtGetByID: %v", err)
+	}
+
+	stmtGetByIDOfUser, err = datastore.DB.Preparex(`
+		SELECT ` + selectColumns + `
+		FROM background_jobs
+		WHERE id = $1 AND user_id = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtGetByIDOfUser: %v", err)
+	}
+
+	// Claiming only succeeds from the queued state so a job is never run twice concurrently
+	stmtClaim, err = datastore.DB.Preparex(`
+		UPDATE background_jobs
+		SET status = 'running', attempts = attempts + 1, started_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'queued'
+		RETURNING ` + selectColumns + `
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtClaim: %v", err)
+	}
+
+	// Outcomes are only recorded by the worker holding the attempt, a job the reaper took
+	// back belongs to whoever claims it next
+	stmtMarkSucceeded, err = datastore.DB.Preparex(`
+		UPDATE background_jobs
+		SET status = 'succeeded', result = CAST($3 AS JSONB), error = NULL, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtMarkSucceeded: %v", err)
+	}
+
+	stmtMarkRetry, err = datastore.DB.Preparex(`
+		UPDATE background_jobs
+		SET status = 'queued', error = $3, updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtMarkRetry: %v", err)
+	}
+
+	stmtMarkFailed, err = datastore.DB.Preparex(`
+		UPDATE background_jobs
+		SET status = 'failed', error = $3, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'running' AND attempts = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtMarkFailed: %v", err)
+	}
+
+	stmtFailQueued, err = datastore.DB.Preparex(`
+		UPDATE background_jobs
+		SET status = 'failed', error = $2, finished_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'queued'
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtFailQueued: %v", err)
+	}
+
+	// Jobs left running by a dead worker go back to the queue, or fail when out of attempts
+	stmtReapStale, err = datastore.DB.Preparex(`
+		UPDATE background_jobs
+		SET status = CASE WHEN attempts >= max_attempts THEN 'failed' ELSE 'queued' END,
+		    error = 'job timed out while running',
+		    finished_at = CASE WHEN attempts >= max_attempts THEN NOW() ELSE finished_at END,
+		    updated_at = NOW()
+		WHERE status = 'running' AND started_at < NOW() - make_interval(secs => $1)
+		RETURNING id, status
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtReapStale: %v", err)
+	}
+
+	// Queued jobs nobody claimed for a while may have lost their Redis entry, touching them
+	// spaces out the pushes of a job still waiting in a long queue
+	stmtTouchQueued, err = datastore.DB.Preparex(`
+		UPDATE background_jobs
+		SET updated_at = NOW()
+		WHERE status = 'queued' AND updated_at < NOW() - make_interval(secs => $1)
+		RETURNING id
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare background_job stmtTouchQueued: %v", err)
+	}
+
+	log.Info("background_job_repo initialized")
+}
+
+// Create inserts a new queued job into the database
+func Create(job *model.BackgroundJob) error {
+	return stmtCreate.QueryRow(job).Scan(&job.ID, &job.Status, &job.CreatedAt, &job.UpdatedAt)
+}
+
+// GetByID retrieves a job by ID regardless of owner, used by the workers
+func GetByID(id int64) (*model.BackgroundJob, error) {
+	job := &model.BackgroundJob{}
+	err := stmtGetByID.Get(job, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetByIDAndUserID retrieves a job by ID and user ID
+func GetByIDAndUserID(id, userID int64) (*model.BackgroundJob, error) {
+	job := &model.BackgroundJob{}
+	err := stmtGetByIDOfUser.Get(job, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Claim moves a queued job to running and increments its attempts.
+// Returns nil when the job is missing or already claimed by another worker.
+func Claim(id int64) (*model.BackgroundJob, error) {
+	job := &model.BackgroundJob{}
+	err := stmtClaim.Get(job, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// MarkSucceeded stores the JSON result of a finished attempt of a job.
+// Returns false when the attempt no longer holds the job.
+func MarkSucceeded(id int64, attempt int, result string) (bool, error) {
+	return execLeased(stmtMarkSucceeded, id, attempt, result)
+}
+
+// MarkRetry puts a job whose attempt failed back to queued, keeping the last error.
+// Returns false when the attempt no longer holds the job.
+func MarkRetry(id int64, attempt int, errMessage string) (bool, error) {
+	return execLeased(stmtMarkRetry, id, attempt, errMessage)
+}
+
+// MarkFailed marks a job as permanently failed after an attempt.
+// Returns false when the attempt no longer holds the job.
+func MarkFailed(id int64, attempt int, errMessage string) (bool, error) {
+	return execLeased(stmtMarkFailed, id, attempt, errMessage)
+}
+
+// FailQueued marks a job no worker claimed yet as permanently failed
+func FailQueued(id int64, errMessage string) error {
+	_, err := stmtFailQueued.Exec(id, errMessage)
+	return err
+}
+
+func execLeased(stmt *sqlx.Stmt, id int64, attempt int, value string) (bool, error) {
+	result, err := stmt.Exec(id, attempt, value)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ReapStale releases the jobs running for longer than timeoutSeconds and returns
+// the IDs of those put back to queued
+func ReapStale(timeoutSeconds int64) ([]int64, error) {
+	var rows []struct {
+		ID     int64  `db:"id"`
+		Status string `db:"status"`
+	}
+	if err := stmtReapStale.Select(&rows, timeoutSeconds); err != nil {
+		return nil, err
+	}
+
+	var requeued []int64
+	for _, row := range rows {
+		if row.Status == "queued" {
+			requeued = append(requeued, row.ID)
+		}
+	}
+	return requeued, nil
+}
+
+// TouchStaleQueued returns the IDs of the jobs queued for longer than graceSeconds without
+// being claimed, restarting their wait
+func TouchStaleQueued(graceSeconds int64) ([]int64, error) {
+	var ids []int64
+	if err := stmtTouchQueued.Select(&ids, graceSeconds); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,75 @@
+package cache_repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"worknote-api/datastore"
+)
+
+// KeyPrefix namespaces every cache key written by the API
+const KeyPrefix = "worknote:cache:"
+
+// GetJSON reads a cached value into dest, returning false on a cache miss
+func GetJSON(key string, dest interface{}) (bool, error) {
+	data, err := datastore.Redis.Get(context.Background(), KeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetJSON stores a value as JSON with the given TTL
+func SetJSON(key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return datastore.Redis.Set(context.Background(), KeyPrefix+key, data, ttl).Err()
+}
+
+// SetTrackedJSON stores a value and records its key in an index set so that
+// all keys of a family can be invalidated together
+func SetTrackedJSON(indexKey, key string, value interface{}, ttl time.Duration) error {
+	if err := SetJSON(key, value, ttl); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pipe := datastore.Redis.TxPipeline()
+	pipe.SAdd(ctx, KeyPrefix+indexKey, key)
+	pipe.Expire(ctx, KeyPrefix+indexKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Delete removes the given keys from the cache
+func Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = KeyPrefix + key
+	}
+	return datastore.Redis.Del(context.Background(), prefixed...).Err()
+}
+
+// DeleteTracked removes every key recorded in the index set, and the set itself
+func DeleteTracked(indexKey string) error {
+	ctx := context.Background()
+	keys, err := datastore.Redis.SMembers(ctx, KeyPrefix+indexKey).Result()
+	if err != nil {
+		return err
+	}
+	return Delete(append(keys, indexKey)...)
+}
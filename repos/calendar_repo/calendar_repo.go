@@ -0,0 +1,127 @@
+package calendar_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+const calendarDayColumns = `
+	id, user_id, TO_CHAR(date, 'YYYY-MM-DD') AS date, kind, name, source, created_at
+`
+
+var (
+	stmtUpsert      *sqlx.NamedStmt
+	stmtListInRange *sqlx.Stmt
+	stmtListAll     *sqlx.Stmt
+	stmtDelete      *sqlx.Stmt
+)
+
+// Initialize prepares all named statements for calendar repository
+func Initialize() {
+	var err error
+
+	stmtUpsert, err = datastore.DB.PrepareNamed(`
+		INSERT INTO calendar_days (user_id, date, kind, name, source)
+		VALUES (:user_id, :date, :kind, :name, :source)
+		ON CONFLICT (user_id, date, kind)
+		DO UPDATE SET name = EXCLUDED.name, source = EXCLUDED.source
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare calendar stmtUpsert: %v", err)
+	}
+
+	stmtListInRange, err = datastore.DB.Preparex(`
+		SELECT ` + calendarDayColumns + `
+		FROM calendar_days
+		WHERE user_id = $1 AND date >= $2 AND date <= $3 AND ($4 = '' OR kind = $4)
+		ORDER BY date, kind
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare calendar stmtListInRange: %v", err)
+	}
+
+	stmtListAll, err = datastore.DB.Preparex(`
+		SELECT ` + calendarDayColumns + `
+		FROM calendar_days
+		WHERE user_id = $1
+		ORDER BY date, kind
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare calendar stmtListAll: %v", err)
+	}
+
+	stmtDelete, err = datastore.DB.Preparex(`
+		DELETE FROM calendar_days
+		WHERE id = $1 AND user_id = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare calendar stmtDelete: %v", err)
+	}
+
+	log.Info("calendar_repo initialized")
+}
+
+// Upsert inserts a calendar day, renaming it when the user already has one of that kind on the date
+func Upsert(day *model.CalendarDay) error {
+	return stmtUpsert.QueryRow(day).Scan(&day.ID, &day.CreatedAt)
+}
+
+// UpsertMany inserts several calendar days in a single transaction
+func UpsertMany(days []model.CalendarDay) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := tx.NamedStmt(stmtUpsert)
+	for i := range days {
+		if err := stmt.QueryRow(&days[i]).Scan(&days[i].ID, &days[i].CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListInRange retrieves the calendar days of a user within a date range, optionally of one kind
+func ListInRange(userID int64, from, to, kind string) ([]model.CalendarDay, error) {
+	var days []model.CalendarDay
+	err := stmtListInRange.Select(&days, userID, from, to, kind)
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// ListByUserID retrieves every calendar day of a user
+func ListByUserID(userID int64) ([]model.CalendarDay, error) {
+	var days []model.CalendarDay
+	err := stmtListAll.Select(&days, userID)
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// Delete removes a calendar day of a user
+func Delete(id, userID int64) error {
+	result, err := stmtDelete.Exec(id, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
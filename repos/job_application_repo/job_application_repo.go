@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	log "github.com/sirupsen/logrus"
@@ -19,6 +20,11 @@ var (
 	stmtDelete        *sqlx.NamedStmt
 	stmtGetByUserID   *sqlx.Stmt
 	stmtCountByUserID *sqlx.Stmt
+
+	stmtListTrashed        *sqlx.Stmt
+	stmtRestore            *sqlx.Stmt
+	stmtPurge              *sqlx.Stmt
+	stmtPurgeDeletedBefore *sqlx.Stmt
 )
 
 // Initialize prepares all named statements for job application repository
@@ -37,7 +43,7 @@ func Initialize() {
 	stmtGetByID, err = datastore.DB.PrepareNamed(`
 		SELECT id, user_id, company_name, job_title, job_url, salary_range, email, notes, state, created_at, updated_at
 		FROM job_applications
-		WHERE id = :id AND user_id = :user_id
+		WHERE id = :id AND user_id = :user_id AND deleted_at IS NULL
 	`)
 	if err != nil {
 		log.Fatalf("failed to prepare job_application stmtGetByID: %v", err)
@@ -48,7 +54,7 @@ func Initialize() {
 		SET company_name = :company_name, job_title = :job_title, job_url = :job_url,
 		    salary_range = :salary_range, email = :email, notes = :notes, state = :state,
 		    updated_at = NOW()
-		WHERE id = :id AND user_id = :user_id
+		WHERE id = :id AND user_id = :user_id AND deleted_at IS NULL
 		RETURNING updated_at
 	`)
 	if err != nil {
@@ -56,8 +62,9 @@ func Initialize() {
 	}
 
 	stmtDelete, err = datastore.DB.PrepareNamed(`
-		DELETE FROM job_applications
-		WHERE id = :id AND user_id = :user_id
+		UPDATE job_applications
+		SET deleted_at = NOW()
+		WHERE id = :id AND user_id = :user_id AND deleted_at IS NULL
 	`)
 	if err != nil {
 		log.Fatalf("failed to prepare job_application stmtDelete: %v", err)
@@ -66,7 +73,7 @@ func Initialize() {
 	stmtGetByUserID, err = datastore.DB.Preparex(`
 		SELECT id, user_id, company_name, job_title, job_url, salary_range, email, notes, state, created_at, updated_at
 		FROM job_applications
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -74,12 +81,48 @@ func Initialize() {
 	}
 
 	stmtCountByUserID, err = datastore.DB.Preparex(`
-		SELECT COUNT(*) FROM job_applications WHERE user_id = $1
+		SELECT COUNT(*) FROM job_applications WHERE user_id = $1 AND deleted_at IS NULL
 	`)
 	if err != nil {
 		log.Fatalf("failed to prepare job_application stmtCountByUserID: %v", err)
 	}
 
+	stmtListTrashed, err = datastore.DB.Preparex(`
+		SELECT id, user_id, company_name, job_title, job_url, salary_range, email, notes, state, created_at, updated_at, deleted_at
+		FROM job_applications
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare job_application stmtListTrashed: %v", err)
+	}
+
+	stmtRestore, err = datastore.DB.Preparex(`
+		UPDATE job_applications
+		SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+		RETURNING id, user_id, company_name, job_title, job_url, salary_range, email, notes, state, created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare job_application stmtRestore: %v", err)
+	}
+
+	stmtPurge, err = datastore.DB.Preparex(`
+		DELETE FROM job_applications
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare job_application stmtPurge: %v", err)
+	}
+
+	stmtPurgeDeletedBefore, err = datastore.DB.Preparex(`
+		DELETE FROM job_applications
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare job_application stmtPurgeDeletedBefore: %v", err)
+	}
+
 	log.Info("job_application_repo initialized")
 }
 
@@ -113,9 +156,9 @@ func GetByUserID(userID int64, search, stateFilter string, limit, offset int) ([
 	baseQuery := `
 		SELECT id, user_id, company_name, job_title, job_url, salary_range, email, notes, state, created_at, updated_at
 		FROM job_applications
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
-	countQuery := `SELECT COUNT(*) FROM job_applications WHERE user_id = $1`
+	countQuery := `SELECT COUNT(*) FROM job_applications WHERE user_id = $1 AND deleted_at IS NULL`
 
 	args := []interface{}{userID}
 	argIndex := 2
@@ -163,7 +206,7 @@ func Update(app *model.JobApplication) error {
 	return stmtUpdate.QueryRow(app).Scan(&app.UpdatedAt)
 }
 
-// Delete removes a job application from the database
+// Delete moves a job application to the trash
 func Delete(id, userID int64) error {
 	result, err := stmtDelete.Exec(map[string]interface{}{"id": id, "user_id": userID})
 	if err != nil {
@@ -178,3 +221,51 @@ func Delete(id, userID int64) error {
 	}
 	return nil
 }
+
+// ListTrashed retrieves the job applications of a user in the trash, most recently deleted first
+func ListTrashed(userID int64) ([]model.JobApplication, error) {
+	var apps []model.JobApplication
+	err := stmtListTrashed.Select(&apps, userID)
+	if err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// Restore takes a job application out of the trash
+func Restore(id, userID int64) (*model.JobApplication, error) {
+	app := &model.JobApplication{}
+	err := stmtRestore.Get(app, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// Purge permanently deletes a trashed job application with its logs
+func Purge(id, userID int64) error {
+	result, err := stmtPurge.Exec(id, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PurgeDeletedBefore permanently deletes every job application trashed before cutoff
+func PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	result, err := stmtPurgeDeletedBefore.Exec(cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
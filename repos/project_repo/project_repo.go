@@ -0,0 +1,150 @@
+package project_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+const projectColumns = `
+	id, user_id, name, tag, COALESCE(color, '') AS color, COALESCE(client, '') AS client,
+	archived, created_at, updated_at
+`
+
+var (
+	stmtCreate     *sqlx.NamedStmt
+	stmtGetByID    *sqlx.NamedStmt
+	stmtGetByTag   *sqlx.NamedStmt
+	stmtListByUser *sqlx.Stmt
+	stmtUpdate     *sqlx.NamedStmt
+	stmtDelete     *sqlx.NamedStmt
+)
+
+// Initialize prepares all named statements for project repository
+func Initialize() {
+	var err error
+
+	stmtCreate, err = datastore.DB.PrepareNamed(`
+		INSERT INTO projects (user_id, name, tag, color, client, archived)
+		VALUES (:user_id, :name, :tag, NULLIF(:color, ''), NULLIF(:client, ''), :archived)
+		RETURNING id, created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare project stmtCreate: %v", err)
+	}
+
+	stmtGetByID, err = datastore.DB.PrepareNamed(`
+		SELECT ` + projectColumns + `
+		FROM projects
+		WHERE id = :id AND user_id = :user_id
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare project stmtGetByID: %v", err)
+	}
+
+	stmtGetByTag, err = datastore.DB.PrepareNamed(`
+		SELECT ` + projectColumns + `
+		FROM projects
+		WHERE user_id = :user_id AND tag = :tag
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare project stmtGetByTag: %v", err)
+	}
+
+	stmtListByUser, err = datastore.DB.Preparex(`
+		SELECT ` + projectColumns + `
+		FROM projects
+		WHERE user_id = $1 AND ($2 OR NOT archived)
+		ORDER BY archived, name
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare project stmtListByUser: %v", err)
+	}
+
+	stmtUpdate, err = datastore.DB.PrepareNamed(`
+		UPDATE projects
+		SET name = :name, tag = :tag, color = NULLIF(:color, ''), client = NULLIF(:client, ''),
+		    archived = :archived, updated_at = NOW()
+		WHERE id = :id AND user_id = :user_id
+		RETURNING updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare project stmtUpdate: %v", err)
+	}
+
+	stmtDelete, err = datastore.DB.PrepareNamed(`
+		DELETE FROM projects
+		WHERE id = :id AND user_id = :user_id
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare project stmtDelete: %v", err)
+	}
+
+	log.Info("project_repo initialized")
+}
+
+// Create inserts a new project into the database
+func Create(project *model.Project) error {
+	return stmtCreate.QueryRow(project).Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt)
+}
+
+// GetByID retrieves a project by ID and user ID
+func GetByID(id, userID int64) (*model.Project, error) {
+	project := &model.Project{}
+	err := stmtGetByID.Get(project, map[string]interface{}{"id": id, "user_id": userID})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// GetByTag retrieves a project by its tag and user ID
+func GetByTag(userID int64, tag string) (*model.Project, error) {
+	project := &model.Project{}
+	err := stmtGetByTag.Get(project, map[string]interface{}{"user_id": userID, "tag": tag})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// ListByUserID retrieves the projects of a user, archived ones only when requested
+func ListByUserID(userID int64, includeArchived bool) ([]model.Project, error) {
+	var projects []model.Project
+	err := stmtListByUser.Select(&projects, userID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// Update updates a project in the database
+func Update(project *model.Project) error {
+	return stmtUpdate.QueryRow(project).Scan(&project.UpdatedAt)
+}
+
+// Delete removes a project and its scoped summaries from the database
+func Delete(id, userID int64) error {
+	result, err := stmtDelete.Exec(map[string]interface{}{"id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
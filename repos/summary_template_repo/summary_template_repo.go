@@ -0,0 +1,183 @@
+package summary_template_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+const templateColumns = `id, user_id, name, content, version, created_at, updated_at`
+
+var (
+	stmtGetByID       *sqlx.Stmt
+	stmtListByUser    *sqlx.Stmt
+	stmtListVersions  *sqlx.Stmt
+	stmtDelete        *sqlx.Stmt
+	stmtInsertVersion *sqlx.Stmt
+)
+
+// Initialize prepares all named statements for summary template repository
+func Initialize() {
+	var err error
+
+	stmtGetByID, err = datastore.DB.Preparex(`
+		SELECT ` + templateColumns + `
+		FROM summary_templates
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare summary_template stmtGetByID: %v", err)
+	}
+
+	stmtListByUser, err = datastore.DB.Preparex(`
+		SELECT ` + templateColumns + `
+		FROM summary_templates
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY name
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare summary_template stmtListByUser: %v", err)
+	}
+
+	stmtListVersions, err = datastore.DB.Preparex(`
+		SELECT v.template_id, v.version, v.content, v.created_at
+		FROM summary_template_versions v
+		JOIN summary_templates t ON t.id = v.template_id
+		WHERE v.template_id = $1 AND t.user_id = $2
+		ORDER BY v.version DESC
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare summary_template stmtListVersions: %v", err)
+	}
+
+	stmtDelete, err = datastore.DB.Preparex(`
+		UPDATE summary_templates
+		SET deleted_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare summary_template stmtDelete: %v", err)
+	}
+
+	stmtInsertVersion, err = datastore.DB.Preparex(`
+		INSERT INTO summary_template_versions (template_id, version, content)
+		VALUES ($1, $2, $3)
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare summary_template stmtInsertVersion: %v", err)
+	}
+
+	log.Info("summary_template_repo initialized")
+}
+
+// Create inserts a new template along with its first version
+func Create(template *model.SummaryTemplate) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowx(`
+		INSERT INTO summary_templates (user_id, name, content, version)
+		VALUES ($1, $2, $3, 1)
+		RETURNING id, version, created_at, updated_at
+	`, template.UserID, template.Name, template.Content).
+		Scan(&template.ID, &template.Version, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Stmtx(stmtInsertVersion).Exec(template.ID, template.Version, template.Content); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update updates a template, recording a new version when its content changed
+func Update(template *model.SummaryTemplate) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldContent string
+	err = tx.QueryRowx(`
+		SELECT content FROM summary_templates
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, template.ID, template.UserID).Scan(&oldContent)
+	if err != nil {
+		return err
+	}
+
+	contentChanged := oldContent != template.Content
+	err = tx.QueryRowx(`
+		UPDATE summary_templates
+		SET name = $3, content = $4, version = version + $5, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING version, updated_at
+	`, template.ID, template.UserID, template.Name, template.Content, boolToInt(contentChanged)).
+		Scan(&template.Version, &template.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if contentChanged {
+		if _, err := tx.Stmtx(stmtInsertVersion).Exec(template.ID, template.Version, template.Content); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves a template by ID and user ID
+func GetByID(id, userID int64) (*model.SummaryTemplate, error) {
+	template := &model.SummaryTemplate{}
+	err := stmtGetByID.Get(template, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// ListByUserID retrieves the templates of a user by name
+func ListByUserID(userID int64) ([]model.SummaryTemplate, error) {
+	var templates []model.SummaryTemplate
+	err := stmtListByUser.Select(&templates, userID)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// ListVersions retrieves every version of a user's template, newest first, deleted templates included
+func ListVersions(id, userID int64) ([]model.SummaryTemplateVersion, error) {
+	var versions []model.SummaryTemplateVersion
+	err := stmtListVersions.Select(&versions, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Delete soft-deletes a template, its versions stay for the summaries it produced
+func Delete(id, userID int64) error {
+	_, err := stmtDelete.Exec(id, userID)
+	return err
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package timer_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+var (
+	stmtStart     *sqlx.NamedStmt
+	stmtGetByUser *sqlx.Stmt
+	stmtDelete    *sqlx.Stmt
+)
+
+// Initialize prepares all named statements for timer repository
+func Initialize() {
+	var err error
+
+	// A user has at most one timer; starting while one runs inserts nothing
+	stmtStart, err = datastore.DB.PrepareNamed(`
+		INSERT INTO timers (user_id, text, project_id, started_at)
+		VALUES (:user_id, :text, NULLIF(:project_id, 0), :started_at)
+		ON CONFLICT (user_id) DO NOTHING
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare timer stmtStart: %v", err)
+	}
+
+	stmtGetByUser, err = datastore.DB.Preparex(`
+		SELECT id, user_id, text, COALESCE(project_id, 0) AS project_id, started_at, created_at
+		FROM timers
+		WHERE user_id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare timer stmtGetByUser: %v", err)
+	}
+
+	// Deleting returns the row so stopping is atomic across concurrent requests
+	stmtDelete, err = datastore.DB.Preparex(`
+		DELETE FROM timers
+		WHERE user_id = $1
+		RETURNING id, user_id, text, COALESCE(project_id, 0) AS project_id, started_at, created_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare timer stmtDelete: %v", err)
+	}
+
+	log.Info("timer_repo initialized")
+}
+
+// Start inserts a running timer. It returns false when the user already has one.
+func Start(timer *model.Timer) (bool, error) {
+	err := stmtStart.QueryRow(timer).Scan(&timer.ID, &timer.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetByUserID retrieves the running timer of a user
+func GetByUserID(userID int64) (*model.Timer, error) {
+	timer := &model.Timer{}
+	err := stmtGetByUser.Get(timer, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return timer, nil
+}
+
+// Delete removes and returns the running timer of a user
+func Delete(userID int64) (*model.Timer, error) {
+	timer := &model.Timer{}
+	err := stmtDelete.Get(timer, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return timer, nil
+}
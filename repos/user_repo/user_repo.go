@@ -4,6 +4,7 @@ import (
 	"database/sql"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 
 	"worknote-api/datastore"
@@ -11,9 +12,14 @@ import (
 )
 
 var (
-	stmtGetByEmail    *sqlx.NamedStmt
-	stmtGetByGoogleID *sqlx.NamedStmt
-	stmtCreate        *sqlx.NamedStmt
+	stmtGetByEmail                  *sqlx.NamedStmt
+	stmtGetByGoogleID               *sqlx.NamedStmt
+	stmtGetByID                     *sqlx.Stmt
+	stmtCreate                      *sqlx.NamedStmt
+	stmtUpdateAutoSummary           *sqlx.Stmt
+	stmtListAutoSummaryUsersInRange *sqlx.Stmt
+	stmtGetWorkdays                 *sqlx.Stmt
+	stmtUpdateWorkdays              *sqlx.Stmt
 )
 
 // Initialize prepares all named statements for user repository
@@ -21,7 +27,7 @@ func Initialize() {
 	var err error
 
 	stmtGetByEmail, err = datastore.DB.PrepareNamed(`
-		SELECT id, email, google_id, username, name, picture_url, role, created_at, updated_at
+		SELECT id, email, google_id, username, name, picture_url, role, auto_summary_enabled, created_at, updated_at
 		FROM users
 		WHERE email = :email
 	`)
@@ -30,7 +36,7 @@ func Initialize() {
 	}
 
 	stmtGetByGoogleID, err = datastore.DB.PrepareNamed(`
-		SELECT id, email, google_id, username, name, picture_url, role, created_at, updated_at
+		SELECT id, email, google_id, username, name, picture_url, role, auto_summary_enabled, created_at, updated_at
 		FROM users
 		WHERE google_id = :google_id
 	`)
@@ -47,6 +53,56 @@ func Initialize() {
 		log.Fatalf("failed to prepare stmtCreate: %v", err)
 	}
 
+	stmtGetByID, err = datastore.DB.Preparex(`
+		SELECT id, email, google_id, username, name, picture_url, role, auto_summary_enabled, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare stmtGetByID: %v", err)
+	}
+
+	stmtUpdateAutoSummary, err = datastore.DB.Preparex(`
+		UPDATE users
+		SET auto_summary_enabled = $2, updated_at = NOW()
+		WHERE id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare stmtUpdateAutoSummary: %v", err)
+	}
+
+	stmtListAutoSummaryUsersInRange, err = datastore.DB.Preparex(`
+		SELECT u.id
+		FROM users u
+		WHERE u.auto_summary_enabled
+		  AND EXISTS (
+		    SELECT 1 FROM work_logs wl
+		    WHERE wl.user_id = u.id AND wl.date >= $1 AND wl.date <= $2 AND wl.deleted_at IS NULL
+		  )
+		ORDER BY u.id
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare stmtListAutoSummaryUsersInRange: %v", err)
+	}
+
+	stmtGetWorkdays, err = datastore.DB.Preparex(`
+		SELECT workdays
+		FROM users
+		WHERE id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare stmtGetWorkdays: %v", err)
+	}
+
+	stmtUpdateWorkdays, err = datastore.DB.Preparex(`
+		UPDATE users
+		SET workdays = $2, updated_at = NOW()
+		WHERE id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare stmtUpdateWorkdays: %v", err)
+	}
+
 	log.Info("user_repo initialized")
 }
 
@@ -80,3 +136,51 @@ func GetByGoogleID(googleID string) (*model.User, error) {
 func Create(user *model.User) error {
 	return stmtCreate.QueryRow(user).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 }
+
+// GetByID retrieves a user by ID
+func GetByID(id int64) (*model.User, error) {
+	user := &model.User{}
+	err := stmtGetByID.Get(user, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateAutoSummaryEnabled sets the scheduled monthly summary opt-in of a user
+func UpdateAutoSummaryEnabled(id int64, enabled bool) error {
+	_, err := stmtUpdateAutoSummary.Exec(id, enabled)
+	return err
+}
+
+// ListAutoSummaryUserIDsInRange retrieves opted-in users having work logs within a date range
+func ListAutoSummaryUserIDsInRange(startDate, endDate string) ([]int64, error) {
+	var ids []int64
+	err := stmtListAutoSummaryUsersInRange.Select(&ids, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetWorkdays retrieves the ISO weekdays (1 = Monday ... 7 = Sunday) a user works on
+func GetWorkdays(id int64) ([]int64, error) {
+	var workdays pq.Int64Array
+	err := stmtGetWorkdays.Get(&workdays, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return workdays, nil
+}
+
+// UpdateWorkdays sets the ISO weekdays a user works on
+func UpdateWorkdays(id int64, workdays []int64) error {
+	_, err := stmtUpdateWorkdays.Exec(id, pq.Int64Array(workdays))
+	return err
+}
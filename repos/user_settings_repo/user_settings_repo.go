@@ -0,0 +1,63 @@
+package user_settings_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+var (
+	stmtGetByUserID *sqlx.Stmt
+	stmtUpsert      *sqlx.NamedStmt
+)
+
+// Initialize prepares all named statements for user settings repository
+func Initialize() {
+	var err error
+
+	stmtGetByUserID, err = datastore.DB.Preparex(`
+		SELECT user_id, timezone, locale, week_start, export_format, COALESCE(ai_provider, '') AS ai_provider,
+		       created_at, updated_at
+		FROM user_settings
+		WHERE user_id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare user settings stmtGetByUserID: %v", err)
+	}
+
+	stmtUpsert, err = datastore.DB.PrepareNamed(`
+		INSERT INTO user_settings (user_id, timezone, locale, week_start, export_format, ai_provider)
+		VALUES (:user_id, :timezone, :locale, :week_start, :export_format, NULLIF(:ai_provider, ''))
+		ON CONFLICT (user_id)
+		DO UPDATE SET timezone = EXCLUDED.timezone, locale = EXCLUDED.locale, week_start = EXCLUDED.week_start,
+		              export_format = EXCLUDED.export_format, ai_provider = EXCLUDED.ai_provider, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare user settings stmtUpsert: %v", err)
+	}
+
+	log.Info("user_settings_repo initialized")
+}
+
+// GetByUserID retrieves the settings of a user, nil when never saved
+func GetByUserID(userID int64) (*model.UserSettings, error) {
+	settings := &model.UserSettings{}
+	err := stmtGetByUserID.Get(settings, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Upsert creates or replaces the settings of a user
+func Upsert(settings *model.UserSettings) error {
+	return stmtUpsert.QueryRow(settings).Scan(&settings.CreatedAt, &settings.UpdatedAt)
+}
@@ -0,0 +1,225 @@
+package webhook_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+const deliveryColumns = `
+	id, webhook_id, event, payload::text AS payload, status, attempts,
+	COALESCE(response_status, 0) AS response_status,
+	COALESCE(error, '') AS error, delivered_at, created_at, updated_at
+`
+
+var (
+	stmtCreate                *sqlx.NamedStmt
+	stmtGetByID               *sqlx.NamedStmt
+	stmtListByUser            *sqlx.Stmt
+	stmtListActiveByEvent     *sqlx.Stmt
+	stmtUpdate                *sqlx.NamedStmt
+	stmtDelete                *sqlx.NamedStmt
+	stmtCreateDelivery        *sqlx.NamedStmt
+	stmtGetDeliveryByID       *sqlx.Stmt
+	stmtListDeliveries        *sqlx.Stmt
+	stmtRecordDeliveryAttempt *sqlx.NamedStmt
+)
+
+// Initialize prepares all named statements for webhook repository
+func Initialize() {
+	var err error
+
+	stmtCreate, err = datastore.DB.PrepareNamed(`
+		INSERT INTO webhooks (user_id, url, secret, events, active)
+		VALUES (:user_id, :url, :secret, :events, :active)
+		RETURNING id, created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtCreate: %v", err)
+	}
+
+	stmtGetByID, err = datastore.DB.PrepareNamed(`
+		SELECT id, user_id, url, secret, events, active, created_at, updated_at
+		FROM webhooks
+		WHERE id = :id AND user_id = :user_id
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtGetByID: %v", err)
+	}
+
+	stmtListByUser, err = datastore.DB.Preparex(`
+		SELECT id, user_id, url, secret, events, active, created_at, updated_at
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtListByUser: %v", err)
+	}
+
+	stmtListActiveByEvent, err = datastore.DB.Preparex(`
+		SELECT id, user_id, url, secret, events, active, created_at, updated_at
+		FROM webhooks
+		WHERE user_id = $1 AND active AND ($2 = ANY(events) OR '*' = ANY(events))
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtListActiveByEvent: %v", err)
+	}
+
+	stmtUpdate, err = datastore.DB.PrepareNamed(`
+		UPDATE webhooks
+		SET url = :url, events = :events, active = :active, updated_at = NOW()
+		WHERE id = :id AND user_id = :user_id
+		RETURNING updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtUpdate: %v", err)
+	}
+
+	stmtDelete, err = datastore.DB.PrepareNamed(`
+		DELETE FROM webhooks
+		WHERE id = :id AND user_id = :user_id
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtDelete: %v", err)
+	}
+
+	stmtCreateDelivery, err = datastore.DB.PrepareNamed(`
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, status)
+		VALUES (:webhook_id, :event, CAST(:payload AS JSONB), 'pending')
+		RETURNING id, status, created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtCreateDelivery: %v", err)
+	}
+
+	stmtGetDeliveryByID, err = datastore.DB.Preparex(`
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtGetDeliveryByID: %v", err)
+	}
+
+	stmtListDeliveries, err = datastore.DB.Preparex(`
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtListDeliveries: %v", err)
+	}
+
+	stmtRecordDeliveryAttempt, err = datastore.DB.PrepareNamed(`
+		UPDATE webhook_deliveries
+		SET status = :status, attempts = attempts + 1,
+		    response_status = NULLIF(:response_status, 0),
+		    error = NULLIF(:error, ''), delivered_at = :delivered_at, updated_at = NOW()
+		WHERE id = :id
+		RETURNING attempts, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare webhook stmtRecordDeliveryAttempt: %v", err)
+	}
+
+	log.Info("webhook_repo initialized")
+}
+
+// Create inserts a new webhook into the database
+func Create(webhook *model.Webhook) error {
+	return stmtCreate.QueryRow(webhook).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+}
+
+// GetByID retrieves a webhook by ID and user ID
+func GetByID(id, userID int64) (*model.Webhook, error) {
+	webhook := &model.Webhook{}
+	err := stmtGetByID.Get(webhook, map[string]interface{}{"id": id, "user_id": userID})
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ListByUserID retrieves all webhooks of a user
+func ListByUserID(userID int64) ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+	err := stmtListByUser.Select(&webhooks, userID)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// ListActiveByEvent retrieves the active webhooks of a user subscribed to an event
+func ListActiveByEvent(userID int64, event string) ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+	err := stmtListActiveByEvent.Select(&webhooks, userID, event)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Update updates a webhook in the database
+func Update(webhook *model.Webhook) error {
+	return stmtUpdate.QueryRow(webhook).Scan(&webhook.UpdatedAt)
+}
+
+// Delete removes a webhook and its delivery log from the database
+func Delete(id, userID int64) error {
+	result, err := stmtDelete.Exec(map[string]interface{}{"id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CreateDelivery inserts a pending delivery into the log
+func CreateDelivery(delivery *model.WebhookDelivery) error {
+	return stmtCreateDelivery.QueryRow(delivery).Scan(&delivery.ID, &delivery.Status, &delivery.CreatedAt, &delivery.UpdatedAt)
+}
+
+// GetDeliveryByID retrieves a delivery by ID
+func GetDeliveryByID(id int64) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{}
+	err := stmtGetDeliveryByID.Get(delivery, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ListDeliveries retrieves the delivery log of a webhook, newest first
+func ListDeliveries(webhookID int64, limit, offset int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := stmtListDeliveries.Select(&deliveries, webhookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RecordDeliveryAttempt stores the outcome of one delivery attempt
+func RecordDeliveryAttempt(delivery *model.WebhookDelivery) error {
+	return stmtRecordDeliveryAttempt.QueryRow(delivery).Scan(&delivery.Attempts, &delivery.UpdatedAt)
+}
@@ -0,0 +1,180 @@
+package work_log_item_repo
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+const itemColumns = `
+	id, work_log_id, user_id, position, text, status,
+	COALESCE(project_id, 0) AS project_id, COALESCE(duration_minutes, 0) AS duration_minutes,
+	started_at, ended_at, created_at, updated_at
+`
+
+var (
+	stmtListByWorkLog   *sqlx.Stmt
+	stmtDeleteNotInList *sqlx.Stmt
+	stmtUpdateInWorkLog *sqlx.Stmt
+	stmtInsertInWorkLog *sqlx.Stmt
+)
+
+// Initialize prepares all named statements for work log item repository
+func Initialize() {
+	var err error
+
+	stmtListByWorkLog, err = datastore.DB.Preparex(`
+		SELECT ` + itemColumns + `
+		FROM work_log_items
+		WHERE work_log_id = $1
+		ORDER BY position ASC, id ASC
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_item stmtListByWorkLog: %v", err)
+	}
+
+	stmtDeleteNotInList, err = datastore.DB.Preparex(`
+		DELETE FROM work_log_items
+		WHERE work_log_id = $1 AND NOT (id = ANY($2))
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_item stmtDeleteNotInList: %v", err)
+	}
+
+	stmtUpdateInWorkLog, err = datastore.DB.Preparex(`
+		UPDATE work_log_items
+		SET position = $3, text = $4, status = $5, project_id = NULLIF($6, 0),
+		    duration_minutes = NULLIF($7, 0), started_at = $8, ended_at = $9, updated_at = NOW()
+		WHERE id = $1 AND work_log_id = $2
+		RETURNING created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_item stmtUpdateInWorkLog: %v", err)
+	}
+
+	stmtInsertInWorkLog, err = datastore.DB.Preparex(`
+		INSERT INTO work_log_items (work_log_id, user_id, position, text, status, project_id, duration_minutes, started_at, ended_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, 0), $8, $9)
+		RETURNING id, created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_item stmtInsertInWorkLog: %v", err)
+	}
+
+	log.Info("work_log_item_repo initialized")
+}
+
+// ListByWorkLogID retrieves the items of a work log in order
+func ListByWorkLogID(workLogID int64) ([]model.WorkLogItem, error) {
+	var items []model.WorkLogItem
+	err := stmtListByWorkLog.Select(&items, workLogID)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListByWorkLogIDTx is ListByWorkLogID within a transaction
+func ListByWorkLogIDTx(tx *sqlx.Tx, workLogID int64) ([]model.WorkLogItem, error) {
+	var items []model.WorkLogItem
+	err := tx.Stmtx(stmtListByWorkLog).Select(&items, workLogID)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ReplaceForWorkLog makes the given list the items of a work log in one transaction.
+// Items with an ID are updated in place so they keep their identity, items without
+// one are inserted, and items no longer listed are deleted. Positions follow the slice order.
+func ReplaceForWorkLog(workLogID, userID int64, items []model.WorkLogItem) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := ReplaceForWorkLogTx(tx, workLogID, userID, items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceForWorkLogTx is ReplaceForWorkLog within a transaction
+func ReplaceForWorkLogTx(tx *sqlx.Tx, workLogID, userID int64, items []model.WorkLogItem) error {
+	keep := pq.Int64Array{}
+	for _, item := range items {
+		if item.ID != 0 {
+			keep = append(keep, item.ID)
+		}
+	}
+	if _, err := tx.Stmtx(stmtDeleteNotInList).Exec(workLogID, keep); err != nil {
+		return err
+	}
+
+	update := tx.Stmtx(stmtUpdateInWorkLog)
+	insert := tx.Stmtx(stmtInsertInWorkLog)
+
+	var err error
+	for i := range items {
+		item := &items[i]
+		item.WorkLogID = workLogID
+		item.UserID = userID
+		item.Position = i
+
+		if item.ID != 0 {
+			err = update.QueryRowx(item.ID, workLogID, item.Position, item.Text, item.Status, item.ProjectID,
+				item.DurationMinutes, item.StartedAt, item.EndedAt).
+				Scan(&item.CreatedAt, &item.UpdatedAt)
+		} else {
+			err = insert.QueryRowx(workLogID, userID, item.Position, item.Text, item.Status, item.ProjectID,
+				item.DurationMinutes, item.StartedAt, item.EndedAt).
+				Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// timesheetGroups maps a group_by value to the SQL key and label of each group
+var timesheetGroups = map[string][2]string{
+	"project": {"COALESCE(p.id::text, '')", "COALESCE(p.name, 'No project')"},
+	"day":     {"TO_CHAR(wl.date, 'YYYY-MM-DD')", "TO_CHAR(wl.date, 'YYYY-MM-DD')"},
+	"week":    {"TO_CHAR(wl.date, 'IYYY-\"W\"IW')", "TO_CHAR(DATE_TRUNC('week', wl.date), 'YYYY-MM-DD')"},
+	// Weeks starting on Sunday are keyed by their first day
+	"week_sunday": {"TO_CHAR(wl.date - EXTRACT(DOW FROM wl.date)::int, 'YYYY-MM-DD')", "TO_CHAR(wl.date - EXTRACT(DOW FROM wl.date)::int, 'YYYY-MM-DD')"},
+}
+
+// SumDurations totals the tracked minutes of a user's items between two dates (inclusive),
+// grouped by project, day, ISO week or week starting on Sunday. Items without a duration are left out.
+func SumDurations(userID int64, from, to, groupBy string) ([]model.TimesheetRow, error) {
+	group, ok := timesheetGroups[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported group_by %q", groupBy)
+	}
+
+	var rows []model.TimesheetRow
+	err := datastore.DB.Select(&rows, `
+		SELECT `+group[0]+` AS key, `+group[1]+` AS label,
+		       SUM(i.duration_minutes) AS minutes, COUNT(*) AS items
+		FROM work_log_items i
+		JOIN work_logs wl ON wl.id = i.work_log_id
+		LEFT JOIN projects p ON p.id = i.project_id
+		WHERE i.user_id = $1 AND wl.date >= $2 AND wl.date <= $3 AND wl.deleted_at IS NULL
+		  AND i.duration_minutes IS NOT NULL
+		GROUP BY 1, 2
+		ORDER BY 2
+	`, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -2,6 +2,10 @@ package work_log_repo
 
 import (
 	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	log "github.com/sirupsen/logrus"
@@ -11,13 +15,58 @@ import (
 )
 
 var (
-	stmtUpsert              *sqlx.NamedStmt
-	stmtGetByDate           *sqlx.NamedStmt
-	stmtListByUser          *sqlx.Stmt
+	stmtUpsert                 *sqlx.NamedStmt
+	stmtInsertIfEmpty          *sqlx.NamedStmt
+	stmtGetByDate              *sqlx.NamedStmt
 	stmtListByUserAndDateRange *sqlx.Stmt
-	stmtDeleteByDate        *sqlx.NamedStmt
+	stmtDeleteByDate           *sqlx.NamedStmt
+	stmtListTrashed            *sqlx.Stmt
+	stmtRestoreByDate          *sqlx.Stmt
+	stmtPurgeByDate            *sqlx.Stmt
+	stmtPurgeDeletedBefore     *sqlx.Stmt
+	stmtPurgeRevisionsByDate   *sqlx.Stmt
+	stmtPurgeRevisionsBefore   *sqlx.Stmt
+	stmtReserveDate            *sqlx.Stmt
+	stmtLockByDate             *sqlx.Stmt
+	stmtDailyStats             *sqlx.Stmt
+	stmtMonthlyStats           *sqlx.Stmt
+	stmtListDatesInRange       *sqlx.Stmt
+	stmtGetLatestBefore        *sqlx.Stmt
 )
 
+// ListFilter narrows and paginates a work log listing
+type ListFilter struct {
+	From  string // inclusive, YYYY-MM-DD
+	To    string // inclusive, YYYY-MM-DD
+	After string // keyset cursor: only dates strictly past this one in the listing order
+	Limit int
+	Asc   bool
+	// TagKind and TagName restrict the listing to logs carrying a tag or mention
+	TagKind string
+	TagName string
+}
+
+// SearchFilter narrows and paginates a full-text search
+type SearchFilter struct {
+	TSQuery string // a to_tsquery expression, built and sanitized by the caller
+	From    string
+	To      string
+	Limit   int
+	Offset  int
+}
+
+// searchResultWithTotal carries the window count alongside each search hit
+type searchResultWithTotal struct {
+	model.WorkLogSearchResult
+	TotalCount int `db:"total_count"`
+}
+
+// workLogWithTotal carries the window count alongside each row
+type workLogWithTotal struct {
+	model.WorkLog
+	TotalCount int `db:"total_count"`
+}
+
 // Initialize prepares all named statements for work log repository
 func Initialize() {
 	var err error
@@ -27,66 +76,230 @@ func Initialize() {
 		VALUES (:user_id, :date, :content)
 		ON CONFLICT (user_id, date)
 		DO UPDATE SET content = EXCLUDED.content, updated_at = NOW()
+		WHERE work_logs.deleted_at IS NULL
 		RETURNING id, created_at, updated_at
 	`)
 	if err != nil {
 		log.Fatalf("failed to prepare work_log stmtUpsert: %v", err)
 	}
 
+	// Only a missing day or one without content is written; trashed days are left as is
+	stmtInsertIfEmpty, err = datastore.DB.PrepareNamed(`
+		INSERT INTO work_logs (user_id, date, content)
+		VALUES (:user_id, :date, :content)
+		ON CONFLICT (user_id, date)
+		DO UPDATE SET content = EXCLUDED.content, updated_at = NOW()
+		WHERE work_logs.deleted_at IS NULL AND BTRIM(work_logs.content, E' \t\r\n') = ''
+		RETURNING id, created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtInsertIfEmpty: %v", err)
+	}
+
 	stmtGetByDate, err = datastore.DB.PrepareNamed(`
 		SELECT id, user_id, date, content, created_at, updated_at
 		FROM work_logs
-		WHERE user_id = :user_id AND date = :date
+		WHERE user_id = :user_id AND date = :date AND deleted_at IS NULL
 	`)
 	if err != nil {
 		log.Fatalf("failed to prepare work_log stmtGetByDate: %v", err)
 	}
 
-	stmtListByUser, err = datastore.DB.Preparex(`
-		SELECT id, user_id, date, content, created_at, updated_at
+	stmtDeleteByDate, err = datastore.DB.PrepareNamed(`
+		UPDATE work_logs
+		SET deleted_at = NOW()
+		WHERE user_id = :user_id AND date = :date AND deleted_at IS NULL
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtDeleteByDate: %v", err)
+	}
+
+	stmtListTrashed, err = datastore.DB.Preparex(`
+		SELECT id, user_id, date, content, created_at, updated_at, deleted_at
 		FROM work_logs
-		WHERE user_id = $1
-		ORDER BY date DESC
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
 	`)
 	if err != nil {
-		log.Fatalf("failed to prepare work_log stmtListByUser: %v", err)
+		log.Fatalf("failed to prepare work_log stmtListTrashed: %v", err)
 	}
 
-	stmtDeleteByDate, err = datastore.DB.PrepareNamed(`
+	stmtRestoreByDate, err = datastore.DB.Preparex(`
+		UPDATE work_logs
+		SET deleted_at = NULL, updated_at = NOW()
+		WHERE user_id = $1 AND date = $2 AND deleted_at IS NOT NULL
+		RETURNING id, user_id, date, content, created_at, updated_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtRestoreByDate: %v", err)
+	}
+
+	stmtPurgeByDate, err = datastore.DB.Preparex(`
 		DELETE FROM work_logs
-		WHERE user_id = :user_id AND date = :date
+		WHERE user_id = $1 AND date = $2 AND deleted_at IS NOT NULL
 	`)
 	if err != nil {
-		log.Fatalf("failed to prepare work_log stmtDeleteByDate: %v", err)
+		log.Fatalf("failed to prepare work_log stmtPurgeByDate: %v", err)
+	}
+
+	stmtPurgeDeletedBefore, err = datastore.DB.Preparex(`
+		DELETE FROM work_logs
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtPurgeDeletedBefore: %v", err)
+	}
+
+	stmtPurgeRevisionsByDate, err = datastore.DB.Preparex(`
+		DELETE FROM work_log_revisions
+		WHERE user_id = $1 AND date = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtPurgeRevisionsByDate: %v", err)
+	}
+
+	stmtPurgeRevisionsBefore, err = datastore.DB.Preparex(`
+		DELETE FROM work_log_revisions r
+		USING work_logs wl
+		WHERE wl.user_id = r.user_id AND wl.date = r.date
+		  AND wl.deleted_at IS NOT NULL AND wl.deleted_at < $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtPurgeRevisionsBefore: %v", err)
+	}
+
+	stmtReserveDate, err = datastore.DB.Preparex(`
+		INSERT INTO work_logs (user_id, date, content)
+		VALUES ($1, $2, '')
+		ON CONFLICT (user_id, date) DO NOTHING
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtReserveDate: %v", err)
+	}
+
+	stmtLockByDate, err = datastore.DB.Preparex(`
+		SELECT id, user_id, date, content, created_at, updated_at, deleted_at
+		FROM work_logs
+		WHERE user_id = $1 AND date = $2
+		FOR UPDATE
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtLockByDate: %v", err)
 	}
 
 	stmtListByUserAndDateRange, err = datastore.DB.Preparex(`
 		SELECT id, user_id, date, content, created_at, updated_at
 		FROM work_logs
-		WHERE user_id = $1 AND date >= $2 AND date <= $3
+		WHERE user_id = $1 AND date >= $2 AND date <= $3 AND deleted_at IS NULL
 		ORDER BY date ASC
 	`)
 	if err != nil {
 		log.Fatalf("failed to prepare work_log stmtListByUserAndDateRange: %v", err)
 	}
 
+	stmtDailyStats, err = datastore.DB.Preparex(`
+		SELECT TO_CHAR(wl.date, 'YYYY-MM-DD') AS date, LENGTH(wl.content) AS chars,
+		       (SELECT COUNT(*) FROM work_log_items i WHERE i.work_log_id = wl.id) AS items
+		FROM work_logs wl
+		WHERE wl.user_id = $1 AND wl.date >= $2 AND wl.date <= $3 AND wl.deleted_at IS NULL
+		ORDER BY wl.date
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtDailyStats: %v", err)
+	}
+
+	stmtMonthlyStats, err = datastore.DB.Preparex(`
+		SELECT TO_CHAR(date, 'YYYY-MM') AS month, COUNT(*) AS days, SUM(LENGTH(content)) AS chars
+		FROM work_logs
+		WHERE user_id = $1 AND date >= $2 AND date <= $3 AND deleted_at IS NULL
+		GROUP BY 1
+		ORDER BY 1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtMonthlyStats: %v", err)
+	}
+
+	stmtListDatesInRange, err = datastore.DB.Preparex(`
+		SELECT TO_CHAR(date, 'YYYY-MM-DD')
+		FROM work_logs
+		WHERE user_id = $1 AND date >= $2 AND date <= $3 AND deleted_at IS NULL
+		ORDER BY date
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtListDatesInRange: %v", err)
+	}
+
+	stmtGetLatestBefore, err = datastore.DB.Preparex(`
+		SELECT id, user_id, date, content, created_at, updated_at
+		FROM work_logs
+		WHERE user_id = $1 AND date < $2 AND deleted_at IS NULL
+		ORDER BY date DESC
+		LIMIT 1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log stmtGetLatestBefore: %v", err)
+	}
+
 	log.Info("work_log_repo initialized")
 }
 
-// Upsert creates or updates a work log entry
+// Upsert creates or updates a work log entry. A day whose work log is in the trash is
+// left as is and nil is returned; it has to be restored or purged first.
 func Upsert(userID int64, date, content string) (*model.WorkLog, error) {
+	return upsert(stmtUpsert, userID, date, content)
+}
+
+// InsertIfEmpty saves the content of a day unless it already has some, checking and
+// writing in one statement. Nil is returned when the day has content or is in the trash.
+func InsertIfEmpty(userID int64, date, content string) (*model.WorkLog, error) {
+	return upsert(stmtInsertIfEmpty, userID, date, content)
+}
+
+// UpsertTx is Upsert within a transaction
+func UpsertTx(tx *sqlx.Tx, userID int64, date, content string) (*model.WorkLog, error) {
+	return upsert(tx.NamedStmt(stmtUpsert), userID, date, content)
+}
+
+func upsert(stmt *sqlx.NamedStmt, userID int64, date, content string) (*model.WorkLog, error) {
 	workLog := &model.WorkLog{
 		UserID:  userID,
 		Date:    date,
 		Content: content,
 	}
-	err := stmtUpsert.QueryRow(workLog).Scan(&workLog.ID, &workLog.CreatedAt, &workLog.UpdatedAt)
+	err := stmt.QueryRow(workLog).Scan(&workLog.ID, &workLog.CreatedAt, &workLog.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 	return workLog, nil
 }
 
+// LockByDate locks the work log of a day until tx ends, so read-modify-write cycles on
+// the day do not interleave. A day without a log is reserved by an empty row, which only
+// outlives tx if content is saved into it; nil is returned for such a day.
+// A trashed work log is returned with DeletedAt set.
+func LockByDate(tx *sqlx.Tx, userID int64, date string) (*model.WorkLog, error) {
+	result, err := tx.Stmtx(stmtReserveDate).Exec(userID, date)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	workLog := &model.WorkLog{}
+	if err := tx.Stmtx(stmtLockByDate).Get(workLog, userID, date); err != nil {
+		return nil, err
+	}
+	if reserved > 0 {
+		return nil, nil
+	}
+	return workLog, nil
+}
+
 // GetByDate retrieves a work log by user ID and date
 func GetByDate(userID int64, date string) (*model.WorkLog, error) {
 	workLog := &model.WorkLog{}
@@ -100,20 +313,163 @@ func GetByDate(userID int64, date string) (*model.WorkLog, error) {
 	return workLog, nil
 }
 
-// ListByUserID retrieves all work logs for a user
-func ListByUserID(userID int64) ([]model.WorkLog, error) {
+// List retrieves one page of a user's work logs and the total matching the date range.
+// Both come from a single query walking the (user_id, date) unique index.
+func List(userID int64, filter ListFilter) ([]model.WorkLog, int, error) {
+	args := []interface{}{userID}
+	argIndex := 2
+
+	var conditions []string
+	if filter.From != "" {
+		conditions = append(conditions, fmt.Sprintf("date >= $%d", argIndex))
+		args = append(args, filter.From)
+		argIndex++
+	}
+	if filter.To != "" {
+		conditions = append(conditions, fmt.Sprintf("date <= $%d", argIndex))
+		args = append(args, filter.To)
+		argIndex++
+	}
+	if filter.TagName != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM work_log_tags t WHERE t.work_log_id = work_logs.id AND t.kind = $%d AND t.name = $%d)",
+			argIndex, argIndex+1,
+		))
+		args = append(args, filter.TagKind, filter.TagName)
+		argIndex += 2
+	}
+
+	innerQuery := `
+		SELECT id, user_id, date, content, created_at, updated_at, COUNT(*) OVER () AS total_count
+		FROM work_logs
+		WHERE user_id = $1 AND deleted_at IS NULL
+	`
+	if len(conditions) > 0 {
+		innerQuery += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	// The cursor is applied outside the window so the total ignores pagination
+	order, cursorOp := "DESC", "<"
+	if filter.Asc {
+		order, cursorOp = "ASC", ">"
+	}
+	query := "SELECT * FROM (" + innerQuery + ") page"
+	if filter.After != "" {
+		query += fmt.Sprintf(" WHERE date %s $%d", cursorOp, argIndex)
+		args = append(args, filter.After)
+		argIndex++
+	}
+	query += fmt.Sprintf(" ORDER BY date %s LIMIT $%d", order, argIndex)
+	args = append(args, filter.Limit)
+
+	var rows []workLogWithTotal
+	if err := datastore.DB.Select(&rows, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	logs := make([]model.WorkLog, len(rows))
+	total := 0
+	for i, row := range rows {
+		logs[i] = row.WorkLog
+		total = row.TotalCount
+	}
+
+	// An empty page past the cursor still needs the total
+	if len(rows) == 0 && filter.After != "" {
+		countQuery := "SELECT COUNT(*) FROM work_logs WHERE user_id = $1 AND deleted_at IS NULL"
+		if len(conditions) > 0 {
+			countQuery += " AND " + strings.Join(conditions, " AND ")
+		}
+		if err := datastore.DB.Get(&total, countQuery, args[:len(args)-2]...); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return logs, total, nil
+}
+
+// DeleteByDate moves a work log to the trash
+func DeleteByDate(userID int64, date string) error {
+	_, err := stmtDeleteByDate.Exec(map[string]interface{}{"user_id": userID, "date": date})
+	return err
+}
+
+// DeleteByDateTx is DeleteByDate within a transaction
+func DeleteByDateTx(tx *sqlx.Tx, userID int64, date string) error {
+	_, err := tx.NamedStmt(stmtDeleteByDate).Exec(map[string]interface{}{"user_id": userID, "date": date})
+	return err
+}
+
+// ListTrashed retrieves the work logs of a user in the trash, most recently deleted first
+func ListTrashed(userID int64) ([]model.WorkLog, error) {
 	var logs []model.WorkLog
-	err := stmtListByUser.Select(&logs, userID)
+	err := stmtListTrashed.Select(&logs, userID)
 	if err != nil {
 		return nil, err
 	}
 	return logs, nil
 }
 
-// DeleteByDate deletes a work log by user ID and date
-func DeleteByDate(userID int64, date string) error {
-	_, err := stmtDeleteByDate.Exec(map[string]interface{}{"user_id": userID, "date": date})
-	return err
+// RestoreByDate takes a work log out of the trash
+func RestoreByDate(userID int64, date string) (*model.WorkLog, error) {
+	workLog := &model.WorkLog{}
+	err := stmtRestoreByDate.Get(workLog, userID, date)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return workLog, nil
+}
+
+// PurgeByDate permanently deletes a trashed work log with its items, tags and revisions
+func PurgeByDate(userID int64, date string) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Stmtx(stmtPurgeByDate).Exec(userID, date)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Stmtx(stmtPurgeRevisionsByDate).Exec(userID, date); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeletedBefore permanently deletes every work log trashed before cutoff, revisions included
+func PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Stmtx(stmtPurgeRevisionsBefore).Exec(cutoff); err != nil {
+		return 0, err
+	}
+	result, err := tx.Stmtx(stmtPurgeDeletedBefore).Exec(cutoff)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return rows, tx.Commit()
 }
 
 // ListByUserIDAndDateRange retrieves work logs for a user within a date range
@@ -125,3 +481,102 @@ func ListByUserIDAndDateRange(userID int64, startDate, endDate string) ([]model.
 	}
 	return logs, nil
 }
+
+// Search runs a ranked full-text search over a user's work logs using the content_tsv GIN index
+func Search(userID int64, filter SearchFilter) ([]model.WorkLogSearchResult, int, error) {
+	args := []interface{}{userID, filter.TSQuery}
+	argIndex := 3
+
+	query := `
+		SELECT id, user_id, date, content, created_at, updated_at,
+		       ts_rank(content_tsv, q) AS rank,
+		       ts_headline('english', content, q, 'StartSel=` + snippetStart + `, StopSel=` + snippetStop + `, MaxFragments=3, MinWords=5, MaxWords=20') AS snippet,
+		       COUNT(*) OVER () AS total_count
+		FROM work_logs, to_tsquery('english', $2) q
+		WHERE user_id = $1 AND deleted_at IS NULL AND content_tsv @@ q
+	`
+	if filter.From != "" {
+		query += fmt.Sprintf(" AND date >= $%d", argIndex)
+		args = append(args, filter.From)
+		argIndex++
+	}
+	if filter.To != "" {
+		query += fmt.Sprintf(" AND date <= $%d", argIndex)
+		args = append(args, filter.To)
+		argIndex++
+	}
+	query += fmt.Sprintf(" ORDER BY rank DESC, date DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filter.Limit, filter.Offset)
+
+	var rows []searchResultWithTotal
+	if err := datastore.DB.Select(&rows, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]model.WorkLogSearchResult, len(rows))
+	total := 0
+	for i, row := range rows {
+		results[i] = row.WorkLogSearchResult
+		results[i].Snippet = highlightSnippet(row.Snippet)
+		total = row.TotalCount
+	}
+
+	return results, total, nil
+}
+
+// Plain markers around the matches of a headline, turned into <mark> once the content is escaped
+const (
+	snippetStart = "@@mark@@"
+	snippetStop  = "@@/mark@@"
+)
+
+// highlightSnippet escapes the work log text of a headline so that only the <mark> tags are markup
+func highlightSnippet(snippet string) string {
+	return strings.NewReplacer(
+		snippetStart, "<mark>",
+		snippetStop, "</mark>",
+	).Replace(html.EscapeString(snippet))
+}
+
+// DailyStats retrieves the size of every logged day of a user in a date range
+func DailyStats(userID int64, from, to string) ([]model.WorkLogDayStat, error) {
+	var stats []model.WorkLogDayStat
+	err := stmtDailyStats.Select(&stats, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// MonthlyStats counts the logged days of a user per month in a date range
+func MonthlyStats(userID int64, from, to string) ([]model.WorkLogMonthStat, error) {
+	var stats []model.WorkLogMonthStat
+	err := stmtMonthlyStats.Select(&stats, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListDatesInRange retrieves the logged dates of a user within a date range, oldest first
+func ListDatesInRange(userID int64, from, to string) ([]string, error) {
+	var dates []string
+	err := stmtListDatesInRange.Select(&dates, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return dates, nil
+}
+
+// GetLatestBefore retrieves the most recent work log of a user strictly before a date
+func GetLatestBefore(userID int64, date string) (*model.WorkLog, error) {
+	workLog := &model.WorkLog{}
+	err := stmtGetLatestBefore.Get(workLog, userID, date)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return workLog, nil
+}
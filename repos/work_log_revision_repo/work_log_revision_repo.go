@@ -0,0 +1,86 @@
+package work_log_revision_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+var (
+	stmtCreate     *sqlx.NamedStmt
+	stmtGetByID    *sqlx.Stmt
+	stmtListByDate *sqlx.Stmt
+)
+
+// Initialize prepares all named statements for work log revision repository
+func Initialize() {
+	var err error
+
+	stmtCreate, err = datastore.DB.PrepareNamed(`
+		INSERT INTO work_log_revisions (user_id, date, content, reason)
+		VALUES (:user_id, :date, :content, :reason)
+		RETURNING id, created_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_revision stmtCreate: %v", err)
+	}
+
+	stmtGetByID, err = datastore.DB.Preparex(`
+		SELECT id, user_id, date, content, reason, created_at
+		FROM work_log_revisions
+		WHERE id = $1 AND user_id = $2 AND date = $3
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_revision stmtGetByID: %v", err)
+	}
+
+	stmtListByDate, err = datastore.DB.Preparex(`
+		SELECT id, user_id, date, content, reason, created_at
+		FROM work_log_revisions
+		WHERE user_id = $1 AND date = $2
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_revision stmtListByDate: %v", err)
+	}
+
+	log.Info("work_log_revision_repo initialized")
+}
+
+// Create inserts a revision into the database
+func Create(revision *model.WorkLogRevision) error {
+	return stmtCreate.QueryRow(revision).Scan(&revision.ID, &revision.CreatedAt)
+}
+
+// CreateTx is Create within a transaction
+func CreateTx(tx *sqlx.Tx, revision *model.WorkLogRevision) error {
+	return tx.NamedStmt(stmtCreate).QueryRow(revision).Scan(&revision.ID, &revision.CreatedAt)
+}
+
+// GetByID retrieves a revision of a user's day
+func GetByID(id, userID int64, date string) (*model.WorkLogRevision, error) {
+	revision := &model.WorkLogRevision{}
+	err := stmtGetByID.Get(revision, id, userID, date)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// ListByDate retrieves the revisions of a user's day, newest first
+func ListByDate(userID int64, date string, limit, offset int) ([]model.WorkLogRevision, error) {
+	var revisions []model.WorkLogRevision
+	err := stmtListByDate.Select(&revisions, userID, date, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
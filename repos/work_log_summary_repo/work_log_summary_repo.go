@@ -10,56 +10,207 @@ import (
 	"worknote-api/model"
 )
 
+const summaryColumns = `
+	s.id, s.user_id, COALESCE(s.project_id, 0) AS project_id, s.period_type,
+	TO_CHAR(s.period_start, 'YYYY-MM-DD') AS period_start, TO_CHAR(s.period_end, 'YYYY-MM-DD') AS period_end,
+	s.summary, COALESCE(v.version, 0) AS version, s.pinned, COALESCE(v.strategy, '') AS strategy,
+	COALESCE(v.template, '') AS template, s.created_at, s.updated_at
+`
+
+// summaryFrom joins the current version to get its number
+const summaryFrom = `
+	FROM work_log_summaries s
+	LEFT JOIN work_log_summary_versions v ON v.id = s.current_version_id
+`
+
+const versionColumns = `
+	id, summary_id, version, summary, source, COALESCE(strategy, '') AS strategy,
+	COALESCE(template, '') AS template, COALESCE(template_id, 0) AS template_id, COALESCE(template_version, 0) AS template_version,
+	COALESCE(provider, '') AS provider, COALESCE(model, '') AS model,
+	COALESCE(prompt_hash, '') AS prompt_hash, prompt_tokens, completion_tokens, created_at
+`
+
 var (
-	stmtUpsert     *sqlx.NamedStmt
-	stmtGetByMonth *sqlx.NamedStmt
+	stmtUpsert           *sqlx.NamedStmt
+	stmtInsertVersion    *sqlx.NamedStmt
+	stmtSetCurrent       *sqlx.Stmt
+	stmtGetByID          *sqlx.Stmt
+	stmtGetByPeriod      *sqlx.NamedStmt
+	stmtListByPeriodType *sqlx.Stmt
+	stmtListVersions     *sqlx.Stmt
+	stmtPin              *sqlx.Stmt
+	stmtUnpin            *sqlx.Stmt
 )
 
+// summaryWithTotal carries the window count alongside each row
+type summaryWithTotal struct {
+	model.WorkLogSummary
+	TotalCount int `db:"total_count"`
+}
+
 // Initialize prepares all named statements for work log summary repository
 func Initialize() {
 	var err error
 
 	stmtUpsert, err = datastore.DB.PrepareNamed(`
-		INSERT INTO work_log_summaries (user_id, month, summary)
-		VALUES (:user_id, :month, :summary)
-		ON CONFLICT (user_id, month)
-		DO UPDATE SET summary = EXCLUDED.summary, updated_at = NOW()
-		RETURNING id, created_at, updated_at
+		INSERT INTO work_log_summaries (user_id, project_id, period_type, period_start, period_end, summary)
+		VALUES (:user_id, NULLIF(:project_id, 0), :period_type, :period_start, :period_end, :summary)
+		ON CONFLICT (user_id, period_type, period_start, period_end, COALESCE(project_id, 0))
+		DO UPDATE SET updated_at = NOW()
+		RETURNING id, pinned
 	`)
 	if err != nil {
 		log.Fatalf("failed to prepare work_log_summary stmtUpsert: %v", err)
 	}
 
-	stmtGetByMonth, err = datastore.DB.PrepareNamed(`
-		SELECT id, user_id, month, summary, created_at, updated_at
-		FROM work_log_summaries
-		WHERE user_id = :user_id AND month = :month
+	// The summary row is locked by the upsert, so version numbers cannot race
+	stmtInsertVersion, err = datastore.DB.PrepareNamed(`
+		INSERT INTO work_log_summary_versions (summary_id, version, summary, source, strategy,
+			template, template_id, template_version, provider, model, prompt_hash, prompt_tokens, completion_tokens)
+		SELECT :summary_id, COALESCE(MAX(version), 0) + 1, :summary, :source, NULLIF(:strategy, ''),
+			NULLIF(:template, ''), NULLIF(:template_id, 0), NULLIF(:template_version, 0),
+			NULLIF(:provider, ''), NULLIF(:model, ''),
+			NULLIF(:prompt_hash, ''), :prompt_tokens, :completion_tokens
+		FROM work_log_summary_versions
+		WHERE summary_id = :summary_id
+		RETURNING id, version, created_at
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_summary stmtInsertVersion: %v", err)
+	}
+
+	stmtSetCurrent, err = datastore.DB.Preparex(`
+		UPDATE work_log_summaries
+		SET current_version_id = $2, summary = $3, updated_at = NOW()
+		WHERE id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_summary stmtSetCurrent: %v", err)
+	}
+
+	stmtGetByID, err = datastore.DB.Preparex(`
+		SELECT ` + summaryColumns + summaryFrom + `
+		WHERE s.id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_summary stmtGetByID: %v", err)
+	}
+
+	stmtGetByPeriod, err = datastore.DB.PrepareNamed(`
+		SELECT ` + summaryColumns + summaryFrom + `
+		WHERE s.user_id = :user_id AND s.period_type = :period_type AND s.period_start = :period_start
+		  AND s.period_end = :period_end AND COALESCE(s.project_id, 0) = :project_id
 	`)
 	if err != nil {
-		log.Fatalf("failed to prepare work_log_summary stmtGetByMonth: %v", err)
+		log.Fatalf("failed to prepare work_log_summary stmtGetByPeriod: %v", err)
+	}
+
+	stmtListByPeriodType, err = datastore.DB.Preparex(`
+		SELECT ` + summaryColumns + `, COUNT(*) OVER() AS total_count` + summaryFrom + `
+		WHERE s.user_id = $1 AND s.period_type = $2 AND COALESCE(s.project_id, 0) = $3
+		ORDER BY s.period_start DESC, s.period_end DESC
+		LIMIT $4 OFFSET $5
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_summary stmtListByPeriodType: %v", err)
+	}
+
+	stmtListVersions, err = datastore.DB.Preparex(`
+		SELECT ` + versionColumns + `
+		FROM work_log_summary_versions
+		WHERE summary_id = $1
+		ORDER BY version DESC
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_summary stmtListVersions: %v", err)
+	}
+
+	stmtPin, err = datastore.DB.Preparex(`
+		UPDATE work_log_summaries s
+		SET current_version_id = v.id, summary = v.summary, pinned = TRUE, updated_at = NOW()
+		FROM work_log_summary_versions v
+		WHERE s.id = $1 AND v.summary_id = s.id AND v.version = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_summary stmtPin: %v", err)
+	}
+
+	stmtUnpin, err = datastore.DB.Preparex(`
+		UPDATE work_log_summaries s
+		SET current_version_id = v.id, summary = v.summary, pinned = FALSE, updated_at = NOW()
+		FROM (
+			SELECT id, summary FROM work_log_summary_versions
+			WHERE summary_id = $1
+			ORDER BY version DESC
+			LIMIT 1
+		) v
+		WHERE s.id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_summary stmtUnpin: %v", err)
 	}
 
 	log.Info("work_log_summary_repo initialized")
 }
 
-// Upsert creates or updates a work log summary entry
-func Upsert(userID int64, month, summary string) (*model.WorkLogSummary, error) {
-	workLogSummary := &model.WorkLogSummary{
-		UserID:  userID,
-		Month:   month,
-		Summary: summary,
+// Upsert adds a version to the summary of a period, creating the summary on its first version.
+// The summary moves to the new version unless another one is pinned. A zero ProjectID is the whole period.
+// On return both carry their saved state, workLogSummary showing its current version.
+func Upsert(workLogSummary *model.WorkLogSummary, version *model.WorkLogSummaryVersion) error {
+	return upsert(workLogSummary, version, false)
+}
+
+// UpsertPinned is Upsert that also pins the new version, both saved or neither
+func UpsertPinned(workLogSummary *model.WorkLogSummary, version *model.WorkLogSummaryVersion) error {
+	return upsert(workLogSummary, version, true)
+}
+
+func upsert(workLogSummary *model.WorkLogSummary, version *model.WorkLogSummaryVersion, pin bool) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
 	}
-	err := stmtUpsert.QueryRow(workLogSummary).Scan(&workLogSummary.ID, &workLogSummary.CreatedAt, &workLogSummary.UpdatedAt)
+	defer tx.Rollback()
+
+	var pinned bool
+	if err := tx.NamedStmt(stmtUpsert).QueryRow(workLogSummary).Scan(&workLogSummary.ID, &pinned); err != nil {
+		return err
+	}
+
+	version.SummaryID = workLogSummary.ID
+	err = tx.NamedStmt(stmtInsertVersion).QueryRow(version).Scan(&version.ID, &version.Version, &version.CreatedAt)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return workLogSummary, nil
+
+	switch {
+	case pin:
+		if _, err := tx.Stmtx(stmtPin).Exec(workLogSummary.ID, version.Version); err != nil {
+			return err
+		}
+	case !pinned:
+		if _, err := tx.Stmtx(stmtSetCurrent).Exec(workLogSummary.ID, version.ID, version.Summary); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Stmtx(stmtGetByID).Get(workLogSummary, workLogSummary.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// GetByMonth retrieves a work log summary by user ID and month
-func GetByMonth(userID int64, month string) (*model.WorkLogSummary, error) {
+// GetByPeriod retrieves the summary of a period and project (zero for the whole period)
+func GetByPeriod(userID int64, periodType, periodStart, periodEnd string, projectID int64) (*model.WorkLogSummary, error) {
 	workLogSummary := &model.WorkLogSummary{}
-	err := stmtGetByMonth.Get(workLogSummary, map[string]interface{}{"user_id": userID, "month": month})
+	err := stmtGetByPeriod.Get(workLogSummary, map[string]interface{}{
+		"user_id":      userID,
+		"period_type":  periodType,
+		"period_start": periodStart,
+		"period_end":   periodEnd,
+		"project_id":   projectID,
+	})
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -68,3 +219,51 @@ func GetByMonth(userID int64, month string) (*model.WorkLogSummary, error) {
 	}
 	return workLogSummary, nil
 }
+
+// ListByPeriodType retrieves a page of a user's summaries of one period type, newest first,
+// along with the total count
+func ListByPeriodType(userID int64, periodType string, projectID int64, limit, offset int) ([]model.WorkLogSummary, int, error) {
+	var rows []summaryWithTotal
+	err := stmtListByPeriodType.Select(&rows, userID, periodType, projectID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	summaries := make([]model.WorkLogSummary, len(rows))
+	total := 0
+	for i, row := range rows {
+		summaries[i] = row.WorkLogSummary
+		total = row.TotalCount
+	}
+	return summaries, total, nil
+}
+
+// ListVersions retrieves every version of a summary, newest first
+func ListVersions(summaryID int64) ([]model.WorkLogSummaryVersion, error) {
+	var versions []model.WorkLogSummaryVersion
+	err := stmtListVersions.Select(&versions, summaryID)
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// Pin makes a version the current text of its summary until unpinned.
+// Returns false when the summary has no such version.
+func Pin(summaryID int64, version int) (bool, error) {
+	result, err := stmtPin.Exec(summaryID, version)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// Unpin lets the summary follow its latest version again
+func Unpin(summaryID int64) error {
+	_, err := stmtUnpin.Exec(summaryID)
+	return err
+}
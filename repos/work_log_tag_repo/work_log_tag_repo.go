@@ -0,0 +1,87 @@
+package work_log_tag_repo
+
+import (
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+var (
+	stmtDeleteByWorkLog *sqlx.Stmt
+	stmtInsert          *sqlx.Stmt
+	stmtCountByUser     *sqlx.Stmt
+)
+
+// Initialize prepares all named statements for work log tag repository
+func Initialize() {
+	var err error
+
+	stmtDeleteByWorkLog, err = datastore.DB.Preparex(`
+		DELETE FROM work_log_tags
+		WHERE work_log_id = $1
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_tag stmtDeleteByWorkLog: %v", err)
+	}
+
+	stmtInsert, err = datastore.DB.Preparex(`
+		INSERT INTO work_log_tags (work_log_id, user_id, kind, name, occurrences)
+		VALUES ($1, $2, $3, $4, $5)
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_tag stmtInsert: %v", err)
+	}
+
+	// Empty kind, from and to disable their filter
+	stmtCountByUser, err = datastore.DB.Preparex(`
+		SELECT t.kind, t.name, COUNT(*) AS days, SUM(t.occurrences) AS occurrences,
+		       TO_CHAR(MAX(wl.date), 'YYYY-MM-DD') AS last_used_on
+		FROM work_log_tags t
+		JOIN work_logs wl ON wl.id = t.work_log_id
+		WHERE t.user_id = $1 AND wl.deleted_at IS NULL
+		  AND ($2 = '' OR t.kind = $2)
+		  AND ($3 = '' OR wl.date >= CAST(NULLIF($3, '') AS DATE))
+		  AND ($4 = '' OR wl.date <= CAST(NULLIF($4, '') AS DATE))
+		GROUP BY t.kind, t.name
+		ORDER BY days DESC, t.name ASC
+		LIMIT $5
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_tag stmtCountByUser: %v", err)
+	}
+
+	log.Info("work_log_tag_repo initialized")
+}
+
+// ReplaceForWorkLog swaps the tags of a work log for the given set in one transaction
+func ReplaceForWorkLog(workLogID, userID int64, tags []model.WorkLogTag) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Stmtx(stmtDeleteByWorkLog).Exec(workLogID); err != nil {
+		return err
+	}
+
+	insert := tx.Stmtx(stmtInsert)
+	for _, tag := range tags {
+		if _, err := insert.Exec(workLogID, userID, tag.Kind, tag.Name, tag.Occurrences); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CountByUserID aggregates tag usage of a user, optionally by kind and date range
+func CountByUserID(userID int64, kind, from, to string, limit int) ([]model.WorkLogTagCount, error) {
+	var counts []model.WorkLogTagCount
+	if err := stmtCountByUser.Select(&counts, userID, kind, from, to, limit); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
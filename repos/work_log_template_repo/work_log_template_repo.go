@@ -0,0 +1,168 @@
+package work_log_template_repo
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/model"
+)
+
+const templateColumns = `id, user_id, name, content, is_default, created_at, updated_at`
+
+var (
+	stmtGetByID      *sqlx.Stmt
+	stmtGetDefault   *sqlx.Stmt
+	stmtListByUser   *sqlx.Stmt
+	stmtDelete       *sqlx.Stmt
+	stmtClearDefault *sqlx.Stmt
+)
+
+// Initialize prepares all named statements for work log template repository
+func Initialize() {
+	var err error
+
+	stmtGetByID, err = datastore.DB.Preparex(`
+		SELECT ` + templateColumns + `
+		FROM work_log_templates
+		WHERE id = $1 AND user_id = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_template stmtGetByID: %v", err)
+	}
+
+	stmtGetDefault, err = datastore.DB.Preparex(`
+		SELECT ` + templateColumns + `
+		FROM work_log_templates
+		WHERE user_id = $1 AND is_default
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_template stmtGetDefault: %v", err)
+	}
+
+	stmtListByUser, err = datastore.DB.Preparex(`
+		SELECT ` + templateColumns + `
+		FROM work_log_templates
+		WHERE user_id = $1
+		ORDER BY is_default DESC, name
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_template stmtListByUser: %v", err)
+	}
+
+	stmtDelete, err = datastore.DB.Preparex(`
+		DELETE FROM work_log_templates
+		WHERE id = $1 AND user_id = $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_template stmtDelete: %v", err)
+	}
+
+	stmtClearDefault, err = datastore.DB.Preparex(`
+		UPDATE work_log_templates
+		SET is_default = FALSE, updated_at = NOW()
+		WHERE user_id = $1 AND is_default AND id <> $2
+	`)
+	if err != nil {
+		log.Fatalf("failed to prepare work_log_template stmtClearDefault: %v", err)
+	}
+
+	log.Info("work_log_template_repo initialized")
+}
+
+// Create inserts a new template, taking over the default flag from another template when set
+func Create(template *model.WorkLogTemplate) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if template.IsDefault {
+		if _, err := tx.Stmtx(stmtClearDefault).Exec(template.UserID, 0); err != nil {
+			return err
+		}
+	}
+	err = tx.QueryRowx(`
+		INSERT INTO work_log_templates (user_id, name, content, is_default)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, template.UserID, template.Name, template.Content, template.IsDefault).
+		Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update updates a template, taking over the default flag from another template when set
+func Update(template *model.WorkLogTemplate) error {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if template.IsDefault {
+		if _, err := tx.Stmtx(stmtClearDefault).Exec(template.UserID, template.ID); err != nil {
+			return err
+		}
+	}
+	err = tx.QueryRowx(`
+		UPDATE work_log_templates
+		SET name = $3, content = $4, is_default = $5, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING updated_at
+	`, template.ID, template.UserID, template.Name, template.Content, template.IsDefault).
+		Scan(&template.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves a template by ID and user ID
+func GetByID(id, userID int64) (*model.WorkLogTemplate, error) {
+	template := &model.WorkLogTemplate{}
+	err := stmtGetByID.Get(template, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// GetDefault retrieves the default template of a user
+func GetDefault(userID int64) (*model.WorkLogTemplate, error) {
+	template := &model.WorkLogTemplate{}
+	err := stmtGetDefault.Get(template, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// ListByUserID retrieves the templates of a user, the default one first
+func ListByUserID(userID int64) ([]model.WorkLogTemplate, error) {
+	var templates []model.WorkLogTemplate
+	err := stmtListByUser.Select(&templates, userID)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Delete removes a template
+func Delete(id, userID int64) error {
+	_, err := stmtDelete.Exec(id, userID)
+	return err
+}
@@ -0,0 +1,367 @@
+package background_job_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/config"
+	"worknote-api/datastore"
+	"worknote-api/model"
+	"worknote-api/repos/background_job_repo"
+)
+
+// Job statuses
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+const (
+	queueKey        = "worknote:jobs:queue"
+	delayedQueueKey = "worknote:jobs:delayed"
+
+	defaultMaxAttempts = 3
+	defaultBackoff     = 30 * time.Second
+	dequeueTimeout     = 5 * time.Second
+	delayedPollPeriod  = time.Second
+	reapPeriod         = time.Minute
+	// reapGrace lets a worker whose job hit its deadline record the outcome before the reaper
+	// takes the job back
+	reapGrace = time.Minute
+	// queuedGrace is how long a queued job may wait unclaimed before it is pushed again, in
+	// case its Redis entry was lost
+	queuedGrace = 10 * time.Minute
+)
+
+// Handler executes a job and returns a JSON-serializable result.
+// ctx expires after the job timeout, past which the job may be handed to another worker.
+type Handler func(ctx context.Context, job *model.BackgroundJob) (interface{}, error)
+
+// Options controls how a job type is retried
+type Options struct {
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on every further attempt
+	Backoff time.Duration
+}
+
+type registration struct {
+	handler Handler
+	options Options
+}
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]registration{}
+)
+
+// Register binds a handler to a job type. It must be called before StartWorkers.
+func Register(jobType string, handler Handler, options Options) {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultMaxAttempts
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = defaultBackoff
+	}
+
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[jobType] = registration{handler: handler, options: options}
+}
+
+// Enqueue records a new job in the database and pushes it onto the Redis queue
+func Enqueue(userID int64, jobType string, payload interface{}) (*model.BackgroundJob, error) {
+	reg, ok := getRegistration(jobType)
+	if !ok {
+		return nil, fmt.Errorf("unknown job type %q", jobType)
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job payload: %w", err)
+	}
+
+	job := &model.BackgroundJob{
+		UserID:      userID,
+		Type:        jobType,
+		Payload:     string(payloadJSON),
+		MaxAttempts: reg.options.MaxAttempts,
+	}
+	if err := background_job_repo.Create(job); err != nil {
+		return nil, err
+	}
+
+	if err := datastore.Redis.LPush(context.Background(), queueKey, job.ID).Err(); err != nil {
+		// No worker would ever pick the row up
+		if markErr := background_job_repo.FailQueued(job.ID, "failed to push job to queue"); markErr != nil {
+			log.Errorf("failed to mark job %d failed: %v", job.ID, markErr)
+		}
+		return nil, fmt.Errorf("failed to push job to queue: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetJob retrieves a job owned by a user
+func GetJob(id, userID int64) (*model.BackgroundJob, error) {
+	return background_job_repo.GetByIDAndUserID(id, userID)
+}
+
+// StartWorkers launches the worker goroutines, the delayed-retry poller and the reaper of
+// jobs abandoned by dead workers. They stop when ctx is cancelled.
+func StartWorkers(ctx context.Context, workers int) {
+	if workers <= 0 {
+		log.Warn("background job workers disabled")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go runWorker(ctx, i)
+	}
+	go runDelayedPoller(ctx)
+	go runReaper(ctx)
+
+	log.Infof("background job workers started: %d", workers)
+}
+
+func runWorker(ctx context.Context, index int) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		values, err := datastore.Redis.BRPop(ctx, dequeueTimeout, queueKey).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Errorf("job worker %d failed to dequeue: %v", index, err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		// BRPop returns [key, value]
+		id, err := strconv.ParseInt(values[1], 10, 64)
+		if err != nil {
+			log.Errorf("job worker %d received invalid job id %q", index, values[1])
+			continue
+		}
+
+		process(id)
+	}
+}
+
+// runDelayedPoller moves retries whose backoff elapsed back onto the main queue
+func runDelayedPoller(ctx context.Context) {
+	ticker := time.NewTicker(delayedPollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ids, err := datastore.Redis.ZRangeByScore(ctx, delayedQueueKey, &redis.ZRangeBy{
+				Min: "-inf",
+				Max: strconv.FormatInt(now.Unix(), 10),
+			}).Result()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Errorf("failed to poll delayed jobs: %v", err)
+				}
+				continue
+			}
+
+			for _, id := range ids {
+				// Only the instance that removes the entry requeues it
+				removed, err := datastore.Redis.ZRem(ctx, delayedQueueKey, id).Result()
+				if err != nil || removed == 0 {
+					continue
+				}
+				if err := datastore.Redis.LPush(ctx, queueKey, id).Err(); err != nil {
+					log.Errorf("failed to requeue delayed job %s: %v", id, err)
+				}
+			}
+		}
+	}
+}
+
+// runReaper requeues jobs whose worker died mid-job, they would stay running forever otherwise,
+// and pushes again the queued jobs whose Redis entry was lost
+func runReaper(ctx context.Context) {
+	ticker := time.NewTicker(reapPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, err := background_job_repo.ReapStale(int64((config.Get().JobTimeout + reapGrace).Seconds()))
+			if err != nil {
+				log.Errorf("failed to reap stale jobs: %v", err)
+				continue
+			}
+
+			for _, id := range ids {
+				log.Warnf("job %d timed out while running, requeueing", id)
+				if err := datastore.Redis.LPush(ctx, queueKey, id).Err(); err != nil {
+					log.Errorf("failed to requeue stale job %d: %v", id, err)
+				}
+			}
+
+			requeueLost(ctx)
+		}
+	}
+}
+
+// requeueLost pushes queued jobs left unclaimed past queuedGrace back onto the queue. Their entry
+// went missing when a push failed or a worker died between dequeueing and claiming; a job
+// still waiting in the queue is at worst pushed twice, and only one claim can succeed.
+func requeueLost(ctx context.Context) {
+	ids, err := background_job_repo.TouchStaleQueued(int64(queuedGrace.Seconds()))
+	if err != nil {
+		log.Errorf("failed to list stale queued jobs: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		// Retries waiting out their backoff are not lost
+		err := datastore.Redis.ZScore(ctx, delayedQueueKey, strconv.FormatInt(id, 10)).Err()
+		if err == nil {
+			continue
+		}
+		if err != redis.Nil {
+			log.Errorf("failed to check delayed job %d: %v", id, err)
+			continue
+		}
+
+		log.Warnf("job %d was left queued, pushing it again", id)
+		if err := datastore.Redis.LPush(ctx, queueKey, id).Err(); err != nil {
+			log.Errorf("failed to requeue job %d: %v", id, err)
+		}
+	}
+}
+
+func process(id int64) {
+	job, err := background_job_repo.Claim(id)
+	if err != nil {
+		log.Errorf("failed to claim job %d: %v", id, err)
+		return
+	}
+	if job == nil {
+		return
+	}
+
+	reg, ok := getRegistration(job.Type)
+	if !ok {
+		fail(job, fmt.Sprintf("no handler registered for job type %q", job.Type))
+		return
+	}
+
+	// The reaper hands the job to another worker past its timeout, stop this run before that
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().JobTimeout)
+	defer cancel()
+
+	result, err := runHandler(ctx, reg.handler, job)
+	if err != nil {
+		if job.Attempts < job.MaxAttempts {
+			retry(job, reg.options, err)
+			return
+		}
+		fail(job, err.Error())
+		return
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		fail(job, fmt.Sprintf("failed to marshal job result: %v", err))
+		return
+	}
+	marked, err := background_job_repo.MarkSucceeded(job.ID, job.Attempts, string(resultJSON))
+	if err != nil {
+		log.Errorf("failed to mark job %d succeeded: %v", job.ID, err)
+		return
+	}
+	if !marked {
+		leaseLost(job)
+	}
+}
+
+// leaseLost reports an attempt that finished after the reaper took its job back
+func leaseLost(job *model.BackgroundJob) {
+	log.Warnf("job %d (%s) attempt %d finished after its timeout, outcome discarded", job.ID, job.Type, job.Attempts)
+}
+
+// runHandler executes a handler, turning panics into job errors
+func runHandler(ctx context.Context, handler Handler, job *model.BackgroundJob) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+func retry(job *model.BackgroundJob, options Options, jobErr error) {
+	delay := options.Backoff << (job.Attempts - 1)
+	log.Warnf("job %d (%s) attempt %d failed, retrying in %s: %v", job.ID, job.Type, job.Attempts, delay, jobErr)
+
+	marked, err := background_job_repo.MarkRetry(job.ID, job.Attempts, jobErr.Error())
+	if err != nil {
+		log.Errorf("failed to mark job %d for retry: %v", job.ID, err)
+		return
+	}
+	if !marked {
+		leaseLost(job)
+		return
+	}
+
+	runAt := time.Now().Add(delay).Unix()
+	err = datastore.Redis.ZAdd(context.Background(), delayedQueueKey, redis.Z{
+		Score:  float64(runAt),
+		Member: job.ID,
+	}).Err()
+	if err != nil {
+		log.Errorf("failed to schedule retry of job %d: %v", job.ID, err)
+	}
+}
+
+func fail(job *model.BackgroundJob, message string) {
+	log.Errorf("job %d (%s) failed: %s", job.ID, job.Type, message)
+	marked, err := background_job_repo.MarkFailed(job.ID, job.Attempts, message)
+	if err != nil {
+		log.Errorf("failed to mark job %d failed: %v", job.ID, err)
+		return
+	}
+	if !marked {
+		leaseLost(job)
+	}
+}
+
+func getRegistration(jobType string) (registration, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	reg, ok := handlers[jobType]
+	return reg, ok
+}
+
+// DecodePayload unmarshals the JSON payload of a job into dest
+func DecodePayload(job *model.BackgroundJob, dest interface{}) error {
+	if err := json.Unmarshal([]byte(job.Payload), dest); err != nil {
+		return errors.New("invalid job payload: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package calendar_service
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/calendar_repo"
+	"worknote-api/repos/user_repo"
+	"worknote-api/repos/work_log_repo"
+	"worknote-api/utils/ics"
+)
+
+// Calendar day kinds
+const (
+	KindHoliday = "holiday"
+	KindLeave   = "leave"
+)
+
+// Calendar day sources
+const (
+	SourceManual = "manual"
+	SourceICS    = "ics"
+)
+
+const (
+	maxGapRangeDays  = 366
+	maxImportedDays  = 1000
+	maxDayNameLength = 200
+)
+
+var dateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// DefaultWorkdays is Monday to Friday
+var DefaultWorkdays = []int{1, 2, 3, 4, 5}
+
+// Calendar tells which days a user is expected to log work on
+type Calendar struct {
+	workdays map[time.Weekday]bool
+	daysOff  map[string]model.CalendarDay
+}
+
+// IsWorkday reports whether the day is one of the user's weekdays and neither a holiday nor leave
+func (c *Calendar) IsWorkday(day time.Time) bool {
+	if !c.workdays[day.Weekday()] {
+		return false
+	}
+	_, off := c.daysOff[day.Format("2006-01-02")]
+	return !off
+}
+
+// DayOff returns the holiday or leave on a date, if any
+func (c *Calendar) DayOff(date string) *model.CalendarDay {
+	day, ok := c.daysOff[date]
+	if !ok {
+		return nil
+	}
+	return &day
+}
+
+// LoadCalendar loads the working weekdays, holidays and leave of a user
+func LoadCalendar(userID int64) (*Calendar, error) {
+	workdays, err := GetWorkdays(userID)
+	if err != nil {
+		return nil, err
+	}
+	days, err := calendar_repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{
+		workdays: make(map[time.Weekday]bool, len(workdays)),
+		daysOff:  make(map[string]model.CalendarDay, len(days)),
+	}
+	for _, weekday := range workdays {
+		cal.workdays[time.Weekday(weekday%7)] = true
+	}
+	for _, day := range days {
+		// Leave wins over a holiday on the same date, it is what the user entered
+		if existing, ok := cal.daysOff[day.Date]; ok && existing.Kind == KindLeave {
+			continue
+		}
+		cal.daysOff[day.Date] = day
+	}
+	return cal, nil
+}
+
+// IsWorkday reports whether a user is expected to log work on a date (YYYY-MM-DD)
+func IsWorkday(userID int64, date string) (bool, error) {
+	day, err := parseDate(date, "date")
+	if err != nil {
+		return false, err
+	}
+	cal, err := LoadCalendar(userID)
+	if err != nil {
+		return false, err
+	}
+	return cal.IsWorkday(day), nil
+}
+
+// GetWorkdays retrieves the ISO weekdays a user works on
+func GetWorkdays(userID int64) ([]int, error) {
+	stored, err := user_repo.GetWorkdays(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stored) == 0 {
+		return DefaultWorkdays, nil
+	}
+	workdays := make([]int, len(stored))
+	for i, weekday := range stored {
+		workdays[i] = int(weekday)
+	}
+	return workdays, nil
+}
+
+// UpdateWorkdays sets the ISO weekdays a user works on
+func UpdateWorkdays(userID int64, req *contract.UpdateWorkdaysRequest) ([]int, error) {
+	if len(req.Workdays) == 0 {
+		return nil, errors.New("workdays must contain at least one weekday")
+	}
+
+	seen := make(map[int]bool, len(req.Workdays))
+	workdays := make([]int, 0, len(req.Workdays))
+	for _, weekday := range req.Workdays {
+		if weekday < 1 || weekday > 7 {
+			return nil, errors.New("workdays must be ISO weekdays between 1 (Monday) and 7 (Sunday)")
+		}
+		if !seen[weekday] {
+			seen[weekday] = true
+			workdays = append(workdays, weekday)
+		}
+	}
+	sort.Ints(workdays)
+
+	stored := make([]int64, len(workdays))
+	for i, weekday := range workdays {
+		stored[i] = int64(weekday)
+	}
+	if err := user_repo.UpdateWorkdays(userID, stored); err != nil {
+		return nil, err
+	}
+	return workdays, nil
+}
+
+// ListDays retrieves the holidays and leave of a user, optionally within a date range and of one kind
+func ListDays(userID int64, req *contract.CalendarDaysRequest) ([]model.CalendarDay, error) {
+	if req.Kind != "" && req.Kind != KindHoliday && req.Kind != KindLeave {
+		return nil, errors.New("kind must be holiday or leave")
+	}
+	if req.From != "" && !dateRegex.MatchString(req.From) {
+		return nil, errors.New("from must be in YYYY-MM-DD format")
+	}
+	if req.To != "" && !dateRegex.MatchString(req.To) {
+		return nil, errors.New("to must be in YYYY-MM-DD format")
+	}
+
+	from, to := req.From, req.To
+	if from == "" {
+		from = "0001-01-01"
+	}
+	if to == "" {
+		to = "9999-12-31"
+	}
+	return calendar_repo.ListInRange(userID, from, to, req.Kind)
+}
+
+// ListDaysOff retrieves the holidays and leave of a user within a date range
+func ListDaysOff(userID int64, from, to string) ([]model.CalendarDay, error) {
+	return calendar_repo.ListInRange(userID, from, to, "")
+}
+
+// AddDay adds a holiday or a leave day, renaming an existing one of the same kind on that date
+func AddDay(userID int64, req *contract.CreateCalendarDayRequest) (*model.CalendarDay, error) {
+	if _, err := parseDate(req.Date, "date"); err != nil {
+		return nil, err
+	}
+	if req.Kind != KindHoliday && req.Kind != KindLeave {
+		return nil, errors.New("kind must be holiday or leave")
+	}
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxDayNameLength {
+		return nil, errors.New("name is too long")
+	}
+
+	day := &model.CalendarDay{
+		UserID: userID,
+		Date:   req.Date,
+		Kind:   req.Kind,
+		Name:   name,
+		Source: SourceManual,
+	}
+	if err := calendar_repo.Upsert(day); err != nil {
+		return nil, err
+	}
+	return day, nil
+}
+
+// DeleteDay removes a holiday or leave day, a missing one is not an error
+func DeleteDay(userID, id int64) error {
+	err := calendar_repo.Delete(id, userID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+// ImportHolidays stores every day covered by the events of an ICS calendar as a holiday
+func ImportHolidays(userID int64, r io.Reader) ([]model.CalendarDay, error) {
+	events, err := ics.Parse(r)
+	if err != nil {
+		return nil, errors.New("invalid ics file: " + err.Error())
+	}
+
+	seen := make(map[string]bool)
+	var days []model.CalendarDay
+	for _, event := range events {
+		name := strings.TrimSpace(event.Summary)
+		if len(name) > maxDayNameLength {
+			name = name[:maxDayNameLength]
+		}
+		dates, ok := event.Days(maxImportedDays)
+		if !ok {
+			return nil, errors.New("ics file covers too many days")
+		}
+		for _, date := range dates {
+			key := date.Format("2006-01-02")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			days = append(days, model.CalendarDay{
+				UserID: userID,
+				Date:   key,
+				Kind:   KindHoliday,
+				Name:   name,
+				Source: SourceICS,
+			})
+			if len(days) > maxImportedDays {
+				return nil, errors.New("ics file covers too many days")
+			}
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	if err := calendar_repo.UpsertMany(days); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// Gaps is the result of looking for working days without a work log
+type Gaps struct {
+	From     string
+	To       string
+	Workdays int
+	Logged   int
+	Dates    []string
+}
+
+// ListGaps finds the working days of a date range the user has not logged
+func ListGaps(userID int64, req *contract.WorkLogGapsRequest) (*Gaps, error) {
+	from, err := parseDate(req.From, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDate(req.To, "to")
+	if err != nil {
+		return nil, err
+	}
+	if from.After(to) {
+		return nil, errors.New("from must be before or equal to to")
+	}
+	if to.Sub(from) > maxGapRangeDays*24*time.Hour {
+		return nil, errors.New("date range must not exceed 366 days")
+	}
+
+	cal, err := LoadCalendar(userID)
+	if err != nil {
+		return nil, err
+	}
+	dates, err := work_log_repo.ListDatesInRange(userID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	logged := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		logged[date] = true
+	}
+
+	gaps := &Gaps{From: req.From, To: req.To, Dates: []string{}}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !cal.IsWorkday(day) {
+			continue
+		}
+		gaps.Workdays++
+		date := day.Format("2006-01-02")
+		if logged[date] {
+			gaps.Logged++
+			continue
+		}
+		gaps.Dates = append(gaps.Dates, date)
+	}
+	return gaps, nil
+}
+
+func parseDate(value, field string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New(field + " is required")
+	}
+	if !dateRegex.MatchString(value) {
+		return time.Time{}, errors.New(field + " must be in YYYY-MM-DD format")
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New(field + " is not a valid date")
+	}
+	return date, nil
+}
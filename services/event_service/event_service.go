@@ -0,0 +1,99 @@
+package event_service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Event types emitted by the services
+const (
+	EventWorkLogUpserted            = "work_log.upserted"
+	EventWorkLogDeleted             = "work_log.deleted"
+	EventJobApplicationStateChanged = "job_application.state_changed"
+	EventSummaryGenerated           = "summary.generated"
+)
+
+// EventTypes lists every event type that can be subscribed to
+var EventTypes = []string{
+	EventWorkLogUpserted,
+	EventWorkLogDeleted,
+	EventJobApplicationStateChanged,
+	EventSummaryGenerated,
+}
+
+// Event is a change notification about a user's data
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	UserID     int64       `json:"-"`
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// Listener receives every published event. Listeners run synchronously on the
+// publishing goroutine, so they must hand off any slow work.
+type Listener func(event *Event)
+
+var (
+	listenersMu sync.RWMutex
+	listeners   []Listener
+)
+
+// Subscribe registers a listener for all events
+func Subscribe(listener Listener) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, listener)
+}
+
+// IsValidEventType reports whether eventType is a known event
+func IsValidEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish notifies every listener about an event of a user.
+// Failures in listeners are logged and never reach the caller.
+func Publish(userID int64, eventType string, data interface{}) {
+	event := &Event{
+		ID:         newEventID(),
+		Type:       eventType,
+		UserID:     userID,
+		Data:       data,
+		OccurredAt: time.Now().UTC(),
+	}
+
+	listenersMu.RLock()
+	current := make([]Listener, len(listeners))
+	copy(current, listeners)
+	listenersMu.RUnlock()
+
+	for _, listener := range current {
+		notify(listener, event)
+	}
+}
+
+func notify(listener Listener, event *Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("event listener panicked on %s: %v", event.Type, r)
+		}
+	}()
+	listener(event)
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return "evt_" + hex.EncodeToString(b)
+}
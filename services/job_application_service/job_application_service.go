@@ -8,6 +8,7 @@ import (
 	"worknote-api/model"
 	"worknote-api/repos/job_application_log_repo"
 	"worknote-api/repos/job_application_repo"
+	"worknote-api/services/event_service"
 )
 
 // Valid job application states
@@ -89,6 +90,7 @@ func UpdateJobApplication(id, userID int64, req *contract.UpdateJobApplicationRe
 	if app == nil {
 		return nil, nil // Not found
 	}
+	previousState := app.State
 
 	// Update fields if provided
 	if req.CompanyName != "" {
@@ -120,10 +122,20 @@ func UpdateJobApplication(id, userID int64, req *contract.UpdateJobApplicationRe
 		return nil, err
 	}
 
+	if app.State != previousState {
+		event_service.Publish(userID, event_service.EventJobApplicationStateChanged, contract.JobApplicationStateChangedEventData{
+			ID:          app.ID,
+			CompanyName: app.CompanyName,
+			JobTitle:    app.JobTitle,
+			FromState:   previousState,
+			ToState:     app.State,
+		})
+	}
+
 	return app, nil
 }
 
-// DeleteJobApplication deletes a job application for a user
+// DeleteJobApplication moves a job application of a user to the trash
 func DeleteJobApplication(id, userID int64) error {
 	err := job_application_repo.Delete(id, userID)
 	if err == sql.ErrNoRows {
@@ -0,0 +1,537 @@
+package llm_service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/config"
+)
+
+// Provider names
+const (
+	ProviderOpenRouter       = "openrouter"
+	ProviderZai              = "zai"
+	ProviderOpenAICompatible = "openai_compatible"
+	ProviderFake             = "fake"
+)
+
+// Message is one chat message of a completion request
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is a chat completion request
+type Request struct {
+	Messages []Message
+}
+
+// Response is the answer of a provider along with what produced it
+type Response struct {
+	Content          string
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// TokenFunc receives streamed content as it arrives, returning an error aborts the stream
+type TokenFunc func(token string) error
+
+// Provider is an LLM backend able to answer chat completions
+type Provider interface {
+	Name() string
+	Model() string
+	Complete(ctx context.Context, req *Request) (*Response, error)
+	Stream(ctx context.Context, req *Request, onToken TokenFunc) (*Response, error)
+}
+
+// SelectableProviders lists the providers a user may pick: the real ones this server is
+// configured for. The fake provider is only meant for the server configuration and tests.
+func SelectableProviders() []string {
+	var names []string
+	for _, name := range []string{ProviderOpenRouter, ProviderZai, ProviderOpenAICompatible} {
+		if _, err := NewProvider(name); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsSelectableProvider checks whether a user may pick a provider
+func IsSelectableProvider(name string) bool {
+	for _, selectable := range SelectableProviders() {
+		if name == selectable {
+			return true
+		}
+	}
+	return false
+}
+
+// NewProvider builds a provider from the configuration
+func NewProvider(name string) (Provider, error) {
+	cfg := config.Get()
+	switch name {
+	case ProviderOpenRouter:
+		if cfg.OpenRouterAPIKey == "" {
+			return nil, errors.New("OPENROUTER_API_KEY is not configured")
+		}
+		return &openAIProvider{
+			name:    ProviderOpenRouter,
+			baseURL: "https://openrouter.ai/api/v1",
+			apiKey:  cfg.OpenRouterAPIKey,
+			model:   cfg.OpenRouterModel,
+		}, nil
+	case ProviderZai:
+		if cfg.ZaiAPIKey == "" {
+			return nil, errors.New("ZAI_API_KEY is not configured")
+		}
+		// https://docs.z.ai/guides/llm/glm-4.7#quick-start
+		return &openAIProvider{
+			name:    ProviderZai,
+			baseURL: "https://api.z.ai/api/paas/v4",
+			apiKey:  cfg.ZaiAPIKey,
+			model:   cfg.ZaiModel,
+			extra: map[string]interface{}{
+				"thinking":    map[string]string{"type": "enabled"},
+				"max_tokens":  4096,
+				"temperature": 1.0,
+			},
+		}, nil
+	case ProviderOpenAICompatible:
+		if cfg.OpenAICompatibleBaseURL == "" {
+			return nil, errors.New("OPENAI_COMPATIBLE_BASE_URL is not configured")
+		}
+		return &openAIProvider{
+			name:    ProviderOpenAICompatible,
+			baseURL: strings.TrimRight(cfg.OpenAICompatibleBaseURL, "/"),
+			apiKey:  cfg.OpenAICompatibleAPIKey,
+			model:   cfg.OpenAICompatibleModel,
+		}, nil
+	case ProviderFake:
+		return fakeProvider{}, nil
+	}
+	return nil, fmt.Errorf("unknown LLM provider %q", name)
+}
+
+// Complete answers a request with the preferred provider, the configured one when empty,
+// and falls back to the configured secondary provider when it fails
+func Complete(ctx context.Context, preferred string, req *Request) (*Response, error) {
+	cfg := config.Get()
+	primary := primaryProvider(preferred)
+
+	resp, err := completeWith(ctx, primary, req)
+	if err == nil {
+		return resp, nil
+	}
+
+	fallback := cfg.LLMFallbackProvider
+	if fallback == "" || fallback == primary || ctx.Err() != nil {
+		return nil, err
+	}
+	log.Warnf("llm provider %s failed, falling back to %s: %v", primary, fallback, err)
+
+	resp, fallbackErr := completeWith(ctx, fallback, req)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("%v; fallback %s: %w", err, fallback, fallbackErr)
+	}
+	return resp, nil
+}
+
+// primaryProvider is the provider a user preferred, or the configured one when the user
+// has none or picked the fake provider before it stopped being selectable
+func primaryProvider(preferred string) string {
+	if preferred == "" || preferred == ProviderFake {
+		return config.Get().LLMProvider
+	}
+	return preferred
+}
+
+// CompletePrompt answers a single user prompt
+func CompletePrompt(ctx context.Context, preferred, prompt string) (*Response, error) {
+	return Complete(ctx, preferred, &Request{Messages: []Message{{Role: "user", Content: prompt}}})
+}
+
+// Stream answers a request like Complete while relaying tokens as the provider produces them.
+// The fallback provider is only tried when the primary one failed before sending any token.
+func Stream(ctx context.Context, preferred string, req *Request, onToken TokenFunc) (*Response, error) {
+	cfg := config.Get()
+	primary := primaryProvider(preferred)
+
+	streamed := false
+	relay := func(token string) error {
+		streamed = true
+		return onToken(token)
+	}
+
+	resp, err := streamWith(ctx, primary, req, relay)
+	if err == nil {
+		return resp, nil
+	}
+
+	fallback := cfg.LLMFallbackProvider
+	if streamed || fallback == "" || fallback == primary || ctx.Err() != nil {
+		return nil, err
+	}
+	log.Warnf("llm provider %s failed, falling back to %s: %v", primary, fallback, err)
+
+	resp, fallbackErr := streamWith(ctx, fallback, req, onToken)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("%v; fallback %s: %w", err, fallback, fallbackErr)
+	}
+	return resp, nil
+}
+
+// StreamPrompt streams the answer to a single user prompt
+func StreamPrompt(ctx context.Context, preferred, prompt string, onToken TokenFunc) (*Response, error) {
+	return Stream(ctx, preferred, &Request{Messages: []Message{{Role: "user", Content: prompt}}}, onToken)
+}
+
+func streamWith(ctx context.Context, name string, req *Request, onToken TokenFunc) (*Response, error) {
+	provider, err := NewProvider(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Get().LLMTimeout)
+	defer cancel()
+
+	resp, err := provider.Stream(ctx, req, onToken)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Content == "" {
+		return nil, fmt.Errorf("empty response from %s", name)
+	}
+	return resp, nil
+}
+
+func completeWith(ctx context.Context, name string, req *Request) (*Response, error) {
+	provider, err := NewProvider(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Get().LLMTimeout)
+	defer cancel()
+
+	resp, err := provider.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Content == "" {
+		return nil, fmt.Errorf("empty response from %s", name)
+	}
+	return resp, nil
+}
+
+// openAIProvider talks to any server exposing the OpenAI chat completions API
+type openAIProvider struct {
+	name    string
+	baseURL string
+	apiKey  string
+	model   string
+	extra   map[string]interface{} // Provider specific request fields
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (p *openAIProvider) Name() string  { return p.name }
+func (p *openAIProvider) Model() string { return p.model }
+
+// finishReasonLength marks an answer stopped by the output token limit
+const finishReasonLength = "length"
+
+// maxErrorBody caps the part of an error answer quoted in the returned error
+const maxErrorBody = 512
+
+type openAIStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := p.post(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	// An error status fails the call so the fallback provider gets its turn
+	if resp.StatusCode != http.StatusOK {
+		return nil, p.statusError(resp.StatusCode, body)
+	}
+
+	var completion openAIResponse
+	if err := json.Unmarshal(body, &completion); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response (status %d): %w", p.name, resp.StatusCode, err)
+	}
+
+	if completion.Error != nil {
+		return nil, fmt.Errorf("%s error: %s", p.name, completion.Error.Message)
+	}
+
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("no response from %s", p.name)
+	}
+	if completion.Choices[0].FinishReason == finishReasonLength {
+		return nil, p.truncatedError()
+	}
+
+	result := &Response{
+		Content:  completion.Choices[0].Message.Content,
+		Provider: p.name,
+		Model:    p.model,
+	}
+	if completion.Model != "" {
+		result.Model = completion.Model
+	}
+	if completion.Usage != nil {
+		result.PromptTokens = completion.Usage.PromptTokens
+		result.CompletionTokens = completion.Usage.CompletionTokens
+	}
+	estimateUsage(result, req)
+	return result, nil
+}
+
+// Stream reads the server-sent chunks of a stream: true completion
+func (p *openAIProvider) Stream(ctx context.Context, req *Request, onToken TokenFunc) (*Response, error) {
+	resp, err := p.post(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Errors come back as a plain JSON body rather than a stream
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, p.statusError(resp.StatusCode, body)
+	}
+
+	result := &Response{Provider: p.name, Model: p.model}
+	var content strings.Builder
+	// A stream cut short by the network or the server ends without either marker
+	done, finishReason := false, ""
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// Comments such as OpenRouter's keep-alive are skipped along with other fields
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse %s stream chunk: %w", p.name, err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("%s error: %s", p.name, chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.PromptTokens = chunk.Usage.PromptTokens
+			result.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].FinishReason != nil {
+			finishReason = *chunk.Choices[0].FinishReason
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		token := chunk.Choices[0].Delta.Content
+		content.WriteString(token)
+		if err := onToken(token); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s stream: %w", p.name, err)
+	}
+	if !done && finishReason == "" {
+		return nil, fmt.Errorf("%s stream ended before the answer was complete", p.name)
+	}
+	if finishReason == finishReasonLength {
+		return nil, p.truncatedError()
+	}
+
+	result.Content = content.String()
+	estimateUsage(result, req)
+	return result, nil
+}
+
+// truncatedError reports an answer cut off at the output token limit
+func (p *openAIProvider) truncatedError() error {
+	return fmt.Errorf("%s answer was cut off at the token limit", p.name)
+}
+
+// estimateUsage fills in the token counts a provider did not report
+func estimateUsage(resp *Response, req *Request) {
+	if resp.PromptTokens != 0 || resp.CompletionTokens != 0 {
+		return
+	}
+	for _, msg := range req.Messages {
+		resp.PromptTokens += EstimateTokens(msg.Content)
+	}
+	resp.CompletionTokens = EstimateTokens(resp.Content)
+}
+
+// statusError describes an answer with an error status, quoting the provider's message or else its body
+func (p *openAIProvider) statusError(status int, body []byte) error {
+	var completion openAIResponse
+	if err := json.Unmarshal(body, &completion); err == nil && completion.Error != nil {
+		return fmt.Errorf("%s returned status %d: %s", p.name, status, completion.Error.Message)
+	}
+
+	text := strings.TrimSpace(strings.ToValidUTF8(string(body), ""))
+	if len(text) > maxErrorBody {
+		text = strings.ToValidUTF8(text[:maxErrorBody], "") + "..."
+	}
+	return fmt.Errorf("%s returned status %d: %s", p.name, status, text)
+}
+
+// post sends a chat completion request and returns the raw HTTP response
+func (p *openAIProvider) post(ctx context.Context, req *Request, stream bool) (*http.Response, error) {
+	reqBody := map[string]interface{}{
+		"model":    p.model,
+		"messages": req.Messages,
+	}
+	for key, value := range p.extra {
+		reqBody[key] = value
+	}
+	if stream {
+		reqBody["stream"] = true
+		// Usage is otherwise left out of streamed answers
+		reqBody["stream_options"] = map[string]bool{"include_usage": true}
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s API: %w", p.name, err)
+	}
+	return resp, nil
+}
+
+// fakeProvider answers deterministically without any network call, for tests and local runs
+type fakeProvider struct{}
+
+func (fakeProvider) Name() string  { return ProviderFake }
+func (fakeProvider) Model() string { return "fake" }
+
+func (fakeProvider) Complete(ctx context.Context, req *Request) (*Response, error) {
+	var prompt strings.Builder
+	for _, msg := range req.Messages {
+		prompt.WriteString(msg.Content)
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(prompt.String()))
+
+	lines := 0
+	for _, line := range strings.Split(prompt.String(), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			lines++
+		}
+	}
+	content := fmt.Sprintf("Fake summary %08x: %d bullet lines, %d characters.", hash.Sum32(), lines, prompt.Len())
+
+	return &Response{
+		Content:          content,
+		Provider:         ProviderFake,
+		Model:            "fake",
+		PromptTokens:     EstimateTokens(prompt.String()),
+		CompletionTokens: EstimateTokens(content),
+	}, nil
+}
+
+// Stream sends the fake answer word by word
+func (f fakeProvider) Stream(ctx context.Context, req *Request, onToken TokenFunc) (*Response, error) {
+	resp, err := f.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	words := strings.SplitAfter(resp.Content, " ")
+	for _, word := range words {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := onToken(word); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// EstimateTokens approximates the token count of a text, about four characters per token
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
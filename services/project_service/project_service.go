@@ -0,0 +1,256 @@
+package project_service
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/project_repo"
+	"worknote-api/services/work_log_service"
+	"worknote-api/services/work_log_tag_service"
+)
+
+var (
+	// Same shape as the tags recognized by work_log_tag_service, so #tag attribution works
+	tagRegex   = regexp.MustCompile(`^[a-z]([a-z0-9_-]*[a-z0-9_])?$`)
+	colorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	slugRegex  = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+// Timeline is one page of a project's bullets, grouped by day
+type Timeline struct {
+	Project    *model.Project
+	Entries    []contract.ProjectTimelineEntry
+	Total      int // days carrying the project's tag
+	NextCursor string
+}
+
+// CreateProject creates a new project for a user
+func CreateProject(userID int64, req *contract.CreateProjectRequest) (*model.Project, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	tag := normalizeTag(req.Tag)
+	if tag == "" {
+		tag = slugify(name)
+	}
+	if err := validateTag(userID, tag, 0); err != nil {
+		return nil, err
+	}
+	if err := validateColor(req.Color); err != nil {
+		return nil, err
+	}
+
+	project := &model.Project{
+		UserID: userID,
+		Name:   name,
+		Tag:    tag,
+		Color:  req.Color,
+		Client: strings.TrimSpace(req.Client),
+	}
+	if err := project_repo.Create(project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// GetProject retrieves a project by ID for a user
+func GetProject(id, userID int64) (*model.Project, error) {
+	return project_repo.GetByID(id, userID)
+}
+
+// ListProjects retrieves the projects of a user
+func ListProjects(userID int64, includeArchived bool) ([]model.Project, error) {
+	projects, err := project_repo.ListByUserID(userID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	if projects == nil {
+		projects = []model.Project{}
+	}
+	return projects, nil
+}
+
+// UpdateProject updates a project for a user
+func UpdateProject(id, userID int64, req *contract.UpdateProjectRequest) (*model.Project, error) {
+	project, err := project_repo.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, nil // Not found
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		project.Name = name
+	}
+	if req.Tag != "" {
+		tag := normalizeTag(req.Tag)
+		if err := validateTag(userID, tag, project.ID); err != nil {
+			return nil, err
+		}
+		project.Tag = tag
+	}
+	if req.Color != nil {
+		if err := validateColor(*req.Color); err != nil {
+			return nil, err
+		}
+		project.Color = *req.Color
+	}
+	if req.Client != nil {
+		project.Client = strings.TrimSpace(*req.Client)
+	}
+	if req.Archived != nil {
+		project.Archived = *req.Archived
+	}
+
+	if err := project_repo.Update(project); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// DeleteProject deletes a project for a user. Work logs keep their #tag bullets.
+func DeleteProject(id, userID int64) error {
+	err := project_repo.Delete(id, userID)
+	if err == sql.ErrNoRows {
+		return nil // Treat as success if not found
+	}
+	return err
+}
+
+// GetTimeline lists, day by day, the bullets attributed to a project
+func GetTimeline(id, userID int64, req *contract.ProjectTimelineRequest, bypassCache bool) (*Timeline, error) {
+	project, err := project_repo.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, nil // Not found
+	}
+
+	page, err := work_log_service.ListWorkLogs(userID, &contract.ListWorkLogsRequest{
+		From:   req.From,
+		To:     req.To,
+		Limit:  req.Limit,
+		Cursor: req.Cursor,
+		Order:  req.Order,
+		Tag:    "#" + project.Tag,
+	}, bypassCache)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]contract.ProjectTimelineEntry, 0, len(page.Logs))
+	for _, workLog := range page.Logs {
+		items := AttributedLines(workLog.Content, project)
+		if len(items) == 0 {
+			continue
+		}
+		date := workLog.Date
+		if len(date) > 10 {
+			date = date[:10]
+		}
+		entries = append(entries, contract.ProjectTimelineEntry{Date: date, Items: items})
+	}
+
+	return &Timeline{
+		Project:    project,
+		Entries:    entries,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// ResolveProject loads the project a download or summary is scoped to.
+// A zero id means no scope and yields nil.
+func ResolveProject(userID, projectID int64) (*model.Project, error) {
+	if projectID == 0 {
+		return nil, nil
+	}
+	project, err := project_repo.GetByID(projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, errors.New("project not found")
+	}
+	return project, nil
+}
+
+// AttributedLines returns the bullets of a content that carry the project's #tag
+func AttributedLines(content string, project *model.Project) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		for _, tag := range work_log_tag_service.ParseTags(line) {
+			if tag.Kind == work_log_tag_service.KindTag && tag.Name == project.Tag {
+				lines = append(lines, line)
+				break
+			}
+		}
+	}
+	return lines
+}
+
+// FilterWorkLogs narrows work logs to the bullets of a project, dropping days without any.
+// A nil project leaves the logs untouched.
+func FilterWorkLogs(logs []model.WorkLog, project *model.Project) []model.WorkLog {
+	if project == nil {
+		return logs
+	}
+	filtered := make([]model.WorkLog, 0, len(logs))
+	for _, workLog := range logs {
+		lines := AttributedLines(workLog.Content, project)
+		if len(lines) == 0 {
+			continue
+		}
+		workLog.Content = strings.Join(lines, "\n")
+		filtered = append(filtered, workLog)
+	}
+	return filtered
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// slugify derives a tag from a project name, e.g. "Acme Website" -> "acme-website"
+func slugify(name string) string {
+	slug := strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug != "" && (slug[0] < 'a' || slug[0] > 'z') {
+		slug = "p-" + slug
+	}
+	return slug
+}
+
+func validateTag(userID int64, tag string, projectID int64) error {
+	if !tagRegex.MatchString(tag) {
+		return errors.New("tag must start with a letter and contain only letters, digits, - or _")
+	}
+	existing, err := project_repo.GetByTag(userID, tag)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != projectID {
+		return errors.New("another project already uses this tag")
+	}
+	return nil
+}
+
+func validateColor(color string) error {
+	if color != "" && !colorRegex.MatchString(color) {
+		return errors.New("color must be in #RRGGBB format")
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package realtime_service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/services/event_service"
+)
+
+// TicketTTL is how long a stream ticket can be redeemed
+const TicketTTL = time.Minute
+
+const (
+	channelPrefix = "worknote:events:user:"
+	ticketPrefix  = "worknote:events:ticket:"
+)
+
+// Message is an event received from the pub/sub channel of a user
+type Message struct {
+	ID   string
+	Type string
+	// Data is the JSON-encoded event, forwarded to clients as-is
+	Data string
+}
+
+// HandleEvent is the event_service listener that publishes events on the user's
+// Redis channel, so every API instance can forward them to its open streams
+func HandleEvent(event *event_service.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("failed to marshal realtime event %s: %v", event.Type, err)
+		return
+	}
+	if err := datastore.Redis.Publish(context.Background(), userChannel(event.UserID), payload).Err(); err != nil {
+		log.Errorf("failed to publish realtime event %s: %v", event.Type, err)
+	}
+}
+
+// IssueTicket creates a short-lived, single-use ticket for clients such as
+// EventSource that cannot send an Authorization header
+func IssueTicket(userID int64) (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ticket: %w", err)
+	}
+	ticket := hex.EncodeToString(b)
+
+	if err := datastore.Redis.Set(context.Background(), ticketPrefix+ticket, userID, TicketTTL).Err(); err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// RedeemTicket consumes a ticket and returns the user it was issued to
+func RedeemTicket(ticket string) (int64, error) {
+	value, err := datastore.Redis.GetDel(context.Background(), ticketPrefix+ticket).Result()
+	if err == redis.Nil {
+		return 0, errors.New("invalid or expired ticket")
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// Subscription is an open pub/sub subscription to a user's events
+type Subscription struct {
+	pubsub   *redis.PubSub
+	Messages <-chan Message
+}
+
+// Subscribe opens a subscription to the events of a user. Close must be called when done.
+func Subscribe(ctx context.Context, userID int64) (*Subscription, error) {
+	pubsub := datastore.Redis.Subscribe(ctx, userChannel(userID))
+	// Wait for the subscription to be confirmed so no event is missed after returning
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	messages := make(chan Message)
+	go func() {
+		defer close(messages)
+		for msg := range pubsub.Channel() {
+			var header struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal([]byte(msg.Payload), &header); err != nil {
+				continue
+			}
+			select {
+			case messages <- Message{ID: header.ID, Type: header.Type, Data: msg.Payload}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return &Subscription{pubsub: pubsub, Messages: messages}, nil
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() error {
+	return s.pubsub.Close()
+}
+
+func userChannel(userID int64) string {
+	return channelPrefix + strconv.FormatInt(userID, 10)
+}
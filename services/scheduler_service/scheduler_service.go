@@ -0,0 +1,168 @@
+package scheduler_service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/datastore"
+	"worknote-api/utils/cron"
+)
+
+const (
+	leaderKey   = "worknote:scheduler:leader"
+	leaderTTL   = 90 * time.Second
+	runLockTTL  = 10 * time.Minute
+	tickPeriod  = time.Minute
+	runLockBase = "worknote:scheduler:run:"
+)
+
+// renewLeaderScript extends the lease only if this instance still holds it
+var renewLeaderScript = redis.NewScript(`
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	end
+	return 0
+`)
+
+// Task is the function executed when a schedule fires
+type Task func(ctx context.Context, scheduledAt time.Time) error
+
+type entry struct {
+	name     string
+	schedule *cron.Schedule
+	task     Task
+}
+
+var (
+	entriesMu  sync.Mutex
+	entries    []entry
+	instanceID = newInstanceID()
+)
+
+// Register adds a named task driven by a cron expression. It must be called before Start.
+func Register(name, expr string, task Task) error {
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for %s: %w", name, err)
+	}
+
+	entriesMu.Lock()
+	defer entriesMu.Unlock()
+	entries = append(entries, entry{name: name, schedule: schedule, task: task})
+	return nil
+}
+
+// Start runs the scheduler loop until ctx is cancelled. Every instance runs the loop,
+// but only the one holding the Redis leader lease executes tasks.
+func Start(ctx context.Context) {
+	go func() {
+		// Align ticks to the start of the next minute
+		next := time.Now().Truncate(tickPeriod).Add(tickPeriod)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		tick(ctx, next)
+		ticker := time.NewTicker(tickPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				releaseLeadership()
+				return
+			case now := <-ticker.C:
+				tick(ctx, now.Truncate(tickPeriod))
+			}
+		}
+	}()
+
+	log.Infof("scheduler started (instance %s)", instanceID)
+}
+
+func tick(ctx context.Context, now time.Time) {
+	if !acquireLeadership(ctx) {
+		return
+	}
+
+	entriesMu.Lock()
+	due := make([]entry, 0, len(entries))
+	for _, e := range entries {
+		if e.schedule.Matches(now) {
+			due = append(due, e)
+		}
+	}
+	entriesMu.Unlock()
+
+	for _, e := range due {
+		// The per-run lock protects against a double run during a leadership handover
+		lockKey := fmt.Sprintf("%s%s:%d", runLockBase, e.name, now.Unix())
+		acquired, err := datastore.Redis.SetNX(ctx, lockKey, instanceID, runLockTTL).Result()
+		if err != nil {
+			log.Errorf("scheduler failed to lock %s: %v", e.name, err)
+			continue
+		}
+		if !acquired {
+			continue
+		}
+
+		go run(ctx, e, now)
+	}
+}
+
+func run(ctx context.Context, e entry, scheduledAt time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("scheduled task %s panicked: %v", e.name, r)
+		}
+	}()
+
+	log.Infof("scheduled task %s started", e.name)
+	if err := e.task(ctx, scheduledAt); err != nil {
+		log.Errorf("scheduled task %s failed: %v", e.name, err)
+		return
+	}
+	log.Infof("scheduled task %s finished", e.name)
+}
+
+// acquireLeadership takes or renews the leader lease
+func acquireLeadership(ctx context.Context) bool {
+	acquired, err := datastore.Redis.SetNX(ctx, leaderKey, instanceID, leaderTTL).Result()
+	if err != nil {
+		log.Errorf("scheduler failed to acquire leadership: %v", err)
+		return false
+	}
+	if acquired {
+		return true
+	}
+
+	renewed, err := renewLeaderScript.Run(ctx, datastore.Redis, []string{leaderKey}, instanceID, leaderTTL.Milliseconds()).Int()
+	if err != nil {
+		log.Errorf("scheduler failed to renew leadership: %v", err)
+		return false
+	}
+	return renewed == 1
+}
+
+func releaseLeadership() {
+	ctx := context.Background()
+	holder, err := datastore.Redis.Get(ctx, leaderKey).Result()
+	if err == nil && holder == instanceID {
+		datastore.Redis.Del(ctx, leaderKey)
+	}
+}
+
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+}
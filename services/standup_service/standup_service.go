@@ -0,0 +1,208 @@
+package standup_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/services/calendar_service"
+	"worknote-api/services/user_settings_service"
+	"worknote-api/services/work_log_item_service"
+	"worknote-api/services/work_log_service"
+	"worknote-api/services/work_log_summary_service"
+	"worknote-api/utils/locale"
+)
+
+// Report formats
+const (
+	FormatMarkdown = "markdown"
+	FormatSlack    = "slack"
+)
+
+// maxLookbackDays bounds the search for the previous working day
+const maxLookbackDays = 31
+
+var (
+	// ErrInvalidFormat is returned for a format other than markdown or slack
+	ErrInvalidFormat = errors.New("format must be markdown or slack")
+	// ErrInvalidDate is returned for a date that is neither YYYY-MM-DD nor relative
+	ErrInvalidDate = errors.New("date must be in YYYY-MM-DD format")
+	// ErrPolishFailed is returned when the AI provider could not rewrite the standup
+	ErrPolishFailed = errors.New("failed to polish standup")
+)
+
+// slackEscaper escapes the characters Slack mrkdwn reserves for links and mentions
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// GetStandup assembles yesterday / today / blockers from the previous working day and the
+// requested day, optionally rewritten by the AI provider
+func GetStandup(userID int64, req *contract.StandupRequest) (*contract.StandupResponse, error) {
+	format := req.Format
+	if format == "" {
+		format = FormatMarkdown
+	}
+	if format != FormatMarkdown && format != FormatSlack {
+		return nil, ErrInvalidFormat
+	}
+
+	dateParam := req.Date
+	if dateParam == "" {
+		dateParam = "today"
+	}
+	date, err := work_log_service.ResolveDate(userID, dateParam)
+	if err != nil {
+		return nil, err
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	previousDate, err := previousWorkday(userID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	previousItems, _, err := work_log_service.ListWorkLogItems(userID, previousDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	todayItems, _, err := work_log_service.ListWorkLogItems(userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	standup := &contract.StandupResponse{
+		Date:         date,
+		PreviousDate: previousDate.Format("2006-01-02"),
+		Format:       format,
+		Yesterday:    []string{},
+		Today:        []string{},
+		Blockers:     []string{},
+	}
+
+	for _, item := range previousItems {
+		if item.Status == work_log_item_service.StatusBlocked {
+			continue
+		}
+		text := item.Text
+		if item.Status == work_log_item_service.StatusInProgress {
+			text += " (in progress)"
+		}
+		standup.Yesterday = append(standup.Yesterday, text)
+	}
+
+	// Without a plan for the day, unfinished work carries over
+	planned := todayItems
+	if len(planned) == 0 {
+		planned = filterStatus(previousItems, work_log_item_service.StatusInProgress)
+	}
+	for _, item := range planned {
+		if item.Status != work_log_item_service.StatusBlocked {
+			standup.Today = append(standup.Today, item.Text)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range append(filterStatus(todayItems, work_log_item_service.StatusBlocked),
+		filterStatus(previousItems, work_log_item_service.StatusBlocked)...) {
+		if !seen[item.Text] {
+			seen[item.Text] = true
+			standup.Blockers = append(standup.Blockers, item.Text)
+		}
+	}
+
+	settings, err := user_settings_service.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	standup.Text = Render(standup, day, previousDate, settings.Locale)
+
+	if req.Polish {
+		polished, err := work_log_summary_service.Complete(userID, buildPolishPrompt(standup.Text, format))
+		if err != nil {
+			log.Errorf("failed to polish standup of user %d: %v", userID, err)
+			return nil, ErrPolishFailed
+		}
+		standup.Text = strings.TrimSpace(polished)
+		standup.Polished = true
+	}
+
+	return standup, nil
+}
+
+// Render writes the sections of a standup in markdown or Slack mrkdwn
+func Render(standup *contract.StandupResponse, day, previousDate time.Time, loc string) string {
+	heading, bullet := "**%s**", "- "
+	escape := func(text string) string { return text }
+	if standup.Format == FormatSlack {
+		heading, bullet = "*%s*", "• "
+		escape = slackEscaper.Replace
+	}
+
+	var sb strings.Builder
+	writeSection := func(title string, lines []string) {
+		sb.WriteString(fmt.Sprintf(heading, title))
+		sb.WriteString("\n")
+		if len(lines) == 0 {
+			sb.WriteString(bullet + "None\n")
+		}
+		for _, line := range lines {
+			sb.WriteString(bullet + escape(line) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf(heading, "Standup "+locale.FormatDate(day, loc)))
+	sb.WriteString("\n\n")
+	writeSection("Yesterday ("+locale.FormatDate(previousDate, loc)+")", standup.Yesterday)
+	writeSection("Today", standup.Today)
+	writeSection("Blockers", standup.Blockers)
+
+	return strings.TrimSpace(sb.String())
+}
+
+// previousWorkday finds the closest working day before a day, skipping weekends, holidays
+// and leave. The day before is used when none is found.
+func previousWorkday(userID int64, day time.Time) (time.Time, error) {
+	cal, err := calendar_service.LoadCalendar(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i := 1; i <= maxLookbackDays; i++ {
+		candidate := day.AddDate(0, 0, -i)
+		if cal.IsWorkday(candidate) {
+			return candidate, nil
+		}
+	}
+	return day.AddDate(0, 0, -1), nil
+}
+
+func filterStatus(items []model.WorkLogItem, status string) []model.WorkLogItem {
+	var filtered []model.WorkLogItem
+	for _, item := range items {
+		if item.Status == status {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+func buildPolishPrompt(text, format string) string {
+	style := "Markdown"
+	if format == FormatSlack {
+		style = "Slack mrkdwn (*bold*, • bullets)"
+	}
+	return fmt.Sprintf(`You are helping a software engineer post an async standup update.
+
+Rewrite the following standup so it reads naturally and concisely for teammates.
+Keep the Yesterday, Today and Blockers sections, do not invent work, and do not add a preamble.
+Format the answer in %s.
+
+%s`, style, text)
+}
@@ -0,0 +1,260 @@
+package summary_template_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/summary_template_repo"
+	"worknote-api/utils/templatecheck"
+)
+
+// Template keys. Built-in styles are identified by their key, user-defined templates by their ID.
+const (
+	KeyDefault           = "default"
+	KeyPerformanceReview = "performance_review"
+	KeyResumeSTAR        = "resume_star"
+	KeyBragDocument      = "brag_document"
+	KeyManagerUpdate     = "manager_update"
+	KeyCustom            = "custom"
+)
+
+// ErrTemplateNotFound is returned when selecting a template the user does not have
+var ErrTemplateNotFound = errors.New("summary template not found")
+
+// Template is a summary prompt ready to be rendered, built-in or user-defined
+type Template struct {
+	Key     string // Built-in style key, or KeyCustom
+	ID      int64  // Set for user-defined templates
+	Name    string
+	Version int
+	Content string
+}
+
+// Variables are the values substituted into a template
+type Variables struct {
+	Logs            string // The formatted work logs, or weekly notes of a long period
+	Period          string // e.g. the month 2026-10 (2026-10-01 to 2026-10-31)
+	PeriodAdjective string // e.g. monthly
+	Project         string // Project name, empty for the whole period
+}
+
+// Built-in styles. Bump the version of a style whenever its content changes.
+var builtins = []Template{
+	{
+		Key:     KeyDefault,
+		Name:    "Summary",
+		Version: 1,
+		Content: `You are a helpful assistant that summarizes work logs.
+
+Please provide a concise but comprehensive summary of the following {{period_adjective}} work activities.
+Highlight key accomplishments, recurring themes, and notable patterns.
+Format the summary in a clear, professional manner.
+If days off are listed, mention the leave briefly and do not treat those days as gaps.
+
+{{logs}}`,
+	},
+	{
+		Key:     KeyPerformanceReview,
+		Name:    "Performance review bullets",
+		Version: 1,
+		Content: `You are a helpful assistant that turns work logs into material for a performance review.
+
+From the following {{period_adjective}} work activities, write bullet points for a self-review.
+Group them under Impact, Execution, Collaboration and Growth, and leave out empty groups.
+Start each bullet with a strong verb, state the outcome first and quantify it when the logs allow.
+Do not invent results that the logs do not support.
+
+{{logs}}`,
+	},
+	{
+		Key:     KeyResumeSTAR,
+		Name:    "Resume achievements (STAR)",
+		Version: 1,
+		Content: `You are a helpful assistant that turns work logs into resume achievements.
+
+From the following {{period_adjective}} work activities, pick the most significant achievements
+and write each one in the STAR format: Situation, Task, Action and Result.
+Then condense each into a single resume bullet of at most 30 words.
+Do not invent results that the logs do not support.
+
+{{logs}}`,
+	},
+	{
+		Key:     KeyBragDocument,
+		Name:    "Brag document",
+		Version: 1,
+		Content: `You are a helpful assistant that keeps a brag document up to date.
+
+From the following {{period_adjective}} work activities, write a brag document entry for {{period}}.
+List wins, projects shipped, problems solved, people helped and things learned, each under its own heading.
+Be specific and keep the details that will help remember the work later, such as names and numbers.
+
+{{logs}}`,
+	},
+	{
+		Key:     KeyManagerUpdate,
+		Name:    "Manager update",
+		Version: 1,
+		Content: `You are a helpful assistant that writes status updates for a manager.
+
+From the following {{period_adjective}} work activities, write a short update covering
+highlights, progress on ongoing work, risks or blockers, and next steps.
+Keep it brief and skimmable, at most a few bullets per section.
+If days off are listed, mention the leave briefly.
+
+{{logs}}`,
+	},
+}
+
+// Builtins returns the built-in summary styles
+func Builtins() []Template {
+	return builtins
+}
+
+// Resolve selects the template of a summary: the user-defined one when templateID is set,
+// else the built-in style of the key, the default style when empty
+func Resolve(userID int64, key string, templateID int64) (*Template, error) {
+	if templateID != 0 {
+		template, err := summary_template_repo.GetByID(templateID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if template == nil {
+			return nil, ErrTemplateNotFound
+		}
+		return fromModel(template), nil
+	}
+
+	if key == "" {
+		key = KeyDefault
+	}
+	for _, builtin := range builtins {
+		if builtin.Key == key {
+			resolved := builtin
+			return &resolved, nil
+		}
+	}
+	return nil, fmt.Errorf("template must be one of %s, or a template_id", strings.Join(builtinKeys(), ", "))
+}
+
+// Render fills the placeholders of a template: {{logs}}, {{period}}, {{period_adjective}} and {{project}}
+func Render(template *Template, vars Variables) string {
+	return strings.NewReplacer(
+		"{{logs}}", vars.Logs,
+		"{{period}}", vars.Period,
+		"{{period_adjective}}", vars.PeriodAdjective,
+		"{{project}}", vars.Project,
+	).Replace(template.Content)
+}
+
+// CreateTemplate creates a summary template for a user
+func CreateTemplate(userID int64, req *contract.CreateSummaryTemplateRequest) (*model.SummaryTemplate, error) {
+	template := &model.SummaryTemplate{
+		UserID:  userID,
+		Name:    strings.TrimSpace(req.Name),
+		Content: req.Content,
+	}
+	if err := validateTemplate(userID, template); err != nil {
+		return nil, err
+	}
+
+	if err := summary_template_repo.Create(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// ListTemplates retrieves the templates of a user
+func ListTemplates(userID int64) ([]model.SummaryTemplate, error) {
+	return summary_template_repo.ListByUserID(userID)
+}
+
+// GetTemplate retrieves a template of a user
+func GetTemplate(id, userID int64) (*model.SummaryTemplate, error) {
+	return summary_template_repo.GetByID(id, userID)
+}
+
+// UpdateTemplate updates a template of a user, a content change making a new version
+func UpdateTemplate(id, userID int64, req *contract.UpdateSummaryTemplateRequest) (*model.SummaryTemplate, error) {
+	template, err := summary_template_repo.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, nil // Not found
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		template.Name = name
+	}
+	if req.Content != nil {
+		template.Content = *req.Content
+	}
+	if err := validateTemplate(userID, template); err != nil {
+		return nil, err
+	}
+
+	if err := summary_template_repo.Update(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteTemplate deletes a template of a user, keeping its versions for the summaries it produced
+func DeleteTemplate(id, userID int64) error {
+	return summary_template_repo.Delete(id, userID)
+}
+
+// ListTemplateVersions retrieves every version of a template of a user, newest first.
+// Versions of a deleted template stay listed so the summaries it produced can be traced.
+func ListTemplateVersions(id, userID int64) ([]model.SummaryTemplateVersion, error) {
+	versions, err := summary_template_repo.ListVersions(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Every template has at least its first version
+	if len(versions) == 0 {
+		return nil, ErrTemplateNotFound
+	}
+	return versions, nil
+}
+
+func fromModel(template *model.SummaryTemplate) *Template {
+	return &Template{
+		Key:     KeyCustom,
+		ID:      template.ID,
+		Name:    template.Name,
+		Version: template.Version,
+		Content: template.Content,
+	}
+}
+
+func builtinKeys() []string {
+	keys := make([]string, len(builtins))
+	for i, builtin := range builtins {
+		keys[i] = builtin.Key
+	}
+	return keys
+}
+
+func validateTemplate(userID int64, template *model.SummaryTemplate) error {
+	if err := templatecheck.Validate(template.Name, template.Content); err != nil {
+		return err
+	}
+	if !strings.Contains(template.Content, "{{logs}}") {
+		return errors.New("content must contain the {{logs}} placeholder")
+	}
+
+	templates, err := summary_template_repo.ListByUserID(userID)
+	if err != nil {
+		return err
+	}
+	names := make(map[int64]string, len(templates))
+	for _, other := range templates {
+		names[other.ID] = other.Name
+	}
+	return templatecheck.CheckUniqueName(template.ID, template.Name, names)
+}
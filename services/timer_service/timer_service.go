@@ -0,0 +1,105 @@
+package timer_service
+
+import (
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/project_repo"
+	"worknote-api/repos/timer_repo"
+	"worknote-api/services/user_settings_service"
+	"worknote-api/services/work_log_item_service"
+	"worknote-api/services/work_log_service"
+)
+
+var (
+	// ErrTimerRunning is returned when starting while a timer already runs
+	ErrTimerRunning = errors.New("a timer is already running")
+	// ErrNoTimer is returned when stopping without a running timer
+	ErrNoTimer = errors.New("no timer is running")
+)
+
+// StartTimer starts the timer of a user
+func StartTimer(userID int64, req *contract.StartTimerRequest) (*model.Timer, error) {
+	parsed, ok := work_log_item_service.ParseItemText(req.Text)
+	if !ok {
+		return nil, errors.New("text must be a single non-empty line")
+	}
+	if req.ProjectID != 0 {
+		project, err := project_repo.GetByID(req.ProjectID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if project == nil {
+			return nil, errors.New("project not found")
+		}
+	}
+
+	timer := &model.Timer{
+		UserID:    userID,
+		Text:      parsed.Text,
+		ProjectID: req.ProjectID,
+		StartedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	started, err := timer_repo.Start(timer)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrTimerRunning
+	}
+
+	return timer, nil
+}
+
+// GetTimer retrieves the running timer of a user
+func GetTimer(userID int64) (*model.Timer, error) {
+	return timer_repo.GetByUserID(userID)
+}
+
+// StopTimer stops the running timer and records it as an item on the day it started,
+// in the user's timezone
+func StopTimer(userID int64, req *contract.StopTimerRequest) (string, *model.WorkLogItem, error) {
+	loc, err := user_settings_service.Location(userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	timer, err := timer_repo.Delete(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if timer == nil {
+		return "", nil, ErrNoTimer
+	}
+
+	text := timer.Text
+	if req.Text != "" {
+		text = req.Text
+	}
+	endedAt := time.Now().UTC().Truncate(time.Second)
+	if !endedAt.After(timer.StartedAt) {
+		endedAt = timer.StartedAt.Add(time.Second)
+	}
+
+	date := timer.StartedAt.In(loc).Format("2006-01-02")
+	item, err := work_log_service.AddWorkLogItem(userID, date, &contract.CreateWorkLogItemRequest{
+		Text:      text,
+		Status:    req.Status,
+		ProjectID: timer.ProjectID,
+		StartedAt: timer.StartedAt.Format(time.RFC3339),
+		EndedAt:   endedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		// Put the timer back so the tracked time is not lost
+		if _, restoreErr := timer_repo.Start(timer); restoreErr != nil {
+			log.Errorf("failed to restore timer of user %d: %v", userID, restoreErr)
+		}
+		return "", nil, err
+	}
+
+	return date, item, nil
+}
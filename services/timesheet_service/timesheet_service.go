@@ -0,0 +1,109 @@
+package timesheet_service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+
+	"worknote-api/contract"
+	"worknote-api/repos/work_log_item_repo"
+	"worknote-api/services/user_settings_service"
+)
+
+var dateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// GetTimesheet totals the tracked time of a user over a date range
+func GetTimesheet(userID int64, req *contract.TimesheetRequest) (*contract.TimesheetResponse, error) {
+	if !dateRegex.MatchString(req.From) {
+		return nil, errors.New("from must be in YYYY-MM-DD format")
+	}
+	if !dateRegex.MatchString(req.To) {
+		return nil, errors.New("to must be in YYYY-MM-DD format")
+	}
+	if req.From > req.To {
+		return nil, errors.New("from must be before or equal to to")
+	}
+
+	groupBy := req.GroupBy
+	if groupBy == "" {
+		groupBy = "project"
+	}
+	if groupBy != "project" && groupBy != "day" && groupBy != "week" {
+		return nil, errors.New("group_by must be project, day or week")
+	}
+
+	group := groupBy
+	if groupBy == "week" {
+		settings, err := user_settings_service.GetSettings(userID)
+		if err != nil {
+			return nil, err
+		}
+		if settings.WeekStart == user_settings_service.WeekStartSunday {
+			group = "week_sunday"
+		}
+	}
+
+	rows, err := work_log_item_repo.SumDurations(userID, req.From, req.To, group)
+	if err != nil {
+		return nil, err
+	}
+
+	timesheet := &contract.TimesheetResponse{
+		From:    req.From,
+		To:      req.To,
+		GroupBy: groupBy,
+		Data:    make([]contract.TimesheetRowResponse, len(rows)),
+	}
+	for i, row := range rows {
+		timesheet.Data[i] = contract.TimesheetRowResponse{
+			Key:     row.Key,
+			Label:   row.Label,
+			Minutes: row.Minutes,
+			Hours:   toHours(row.Minutes),
+			Items:   row.Items,
+		}
+		timesheet.TotalMinutes += row.Minutes
+	}
+	timesheet.TotalHours = toHours(timesheet.TotalMinutes)
+
+	return timesheet, nil
+}
+
+// RenderCSV renders a timesheet with a trailing total row
+func RenderCSV(timesheet *contract.TimesheetResponse) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{{timesheet.GroupBy, "label", "minutes", "hours", "items"}}
+	items := 0
+	for _, row := range timesheet.Data {
+		records = append(records, []string{
+			row.Key, row.Label, strconv.Itoa(row.Minutes), formatHours(row.Hours), strconv.Itoa(row.Items),
+		})
+		items += row.Items
+	}
+	records = append(records, []string{
+		"total", "", strconv.Itoa(timesheet.TotalMinutes), formatHours(timesheet.TotalHours), strconv.Itoa(items),
+	})
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateFilename creates a filename for the CSV download
+func GenerateFilename(timesheet *contract.TimesheetResponse) string {
+	return "timesheet-" + timesheet.From + "-to-" + timesheet.To + "-by-" + timesheet.GroupBy + ".csv"
+}
+
+func toHours(minutes int) float64 {
+	return math.Round(float64(minutes)/60*100) / 100
+}
+
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(hours, 'f', 2, 64)
+}
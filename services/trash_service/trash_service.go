@@ -0,0 +1,132 @@
+package trash_service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/config"
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/job_application_repo"
+	"worknote-api/repos/work_log_repo"
+	"worknote-api/services/event_service"
+	"worknote-api/services/work_log_service"
+)
+
+var dateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// ErrInvalidDate is returned when the date of a trashed work log is not a valid YYYY-MM-DD date
+var ErrInvalidDate = errors.New("date must be a valid date in YYYY-MM-DD format")
+
+// Trash holds the deleted records of a user awaiting restore or purge
+type Trash struct {
+	WorkLogs        []model.WorkLog
+	JobApplications []model.JobApplication
+}
+
+// ListTrash retrieves everything a user has in the trash
+func ListTrash(userID int64) (*Trash, error) {
+	workLogs, err := work_log_repo.ListTrashed(userID)
+	if err != nil {
+		return nil, err
+	}
+	jobApplications, err := job_application_repo.ListTrashed(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trash := &Trash{WorkLogs: workLogs, JobApplications: jobApplications}
+	if trash.WorkLogs == nil {
+		trash.WorkLogs = []model.WorkLog{}
+	}
+	if trash.JobApplications == nil {
+		trash.JobApplications = []model.JobApplication{}
+	}
+	return trash, nil
+}
+
+// PurgeAt is when a record deleted at deletedAt is permanently removed
+func PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.AddDate(0, 0, config.Get().TrashRetentionDays)
+}
+
+// RestoreWorkLog takes the work log of a day out of the trash
+func RestoreWorkLog(userID int64, date string) (*model.WorkLog, error) {
+	if err := checkDate(date); err != nil {
+		return nil, err
+	}
+	workLog, err := work_log_repo.RestoreByDate(userID, date)
+	if err != nil || workLog == nil {
+		return nil, err
+	}
+	work_log_service.InvalidateCache(userID, date)
+
+	event_service.Publish(userID, event_service.EventWorkLogUpserted, contract.WorkLogEventData{
+		ID:      workLog.ID,
+		Date:    workLog.Date,
+		Content: workLog.Content,
+	})
+
+	return workLog, nil
+}
+
+// PurgeWorkLog permanently deletes the trashed work log of a day
+func PurgeWorkLog(userID int64, date string) error {
+	if err := checkDate(date); err != nil {
+		return err
+	}
+	err := work_log_repo.PurgeByDate(userID, date)
+	if err == sql.ErrNoRows {
+		return nil // Treat as success if not found
+	}
+	return err
+}
+
+// checkDate rejects dates Postgres would fail to read
+func checkDate(date string) error {
+	if !dateRegex.MatchString(date) {
+		return ErrInvalidDate
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return ErrInvalidDate
+	}
+	return nil
+}
+
+// RestoreJobApplication takes a job application out of the trash
+func RestoreJobApplication(id, userID int64) (*model.JobApplication, error) {
+	return job_application_repo.Restore(id, userID)
+}
+
+// PurgeJobApplication permanently deletes a trashed job application
+func PurgeJobApplication(id, userID int64) error {
+	err := job_application_repo.Purge(id, userID)
+	if err == sql.ErrNoRows {
+		return nil // Treat as success if not found
+	}
+	return err
+}
+
+// RunPurge is the scheduled task that permanently deletes records kept in the trash
+// longer than the configured retention
+func RunPurge(ctx context.Context, scheduledAt time.Time) error {
+	cutoff := scheduledAt.AddDate(0, 0, -config.Get().TrashRetentionDays)
+
+	workLogs, err := work_log_repo.PurgeDeletedBefore(cutoff)
+	if err != nil {
+		return err
+	}
+	jobApplications, err := job_application_repo.PurgeDeletedBefore(cutoff)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("trash purge: removed %d work logs and %d job applications deleted before %s",
+		workLogs, jobApplications, cutoff.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,148 @@
+package user_settings_service
+
+import (
+	"errors"
+	"strings"
+	"time"
+	_ "time/tzdata" // timezones must resolve even on hosts without a zoneinfo database
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/user_settings_repo"
+	"worknote-api/services/llm_service"
+)
+
+// Week starts
+const (
+	WeekStartMonday = "monday"
+	WeekStartSunday = "sunday"
+)
+
+// Export formats
+const (
+	ExportMarkdown = "markdown"
+	ExportCSV      = "csv"
+	ExportJSON     = "json"
+)
+
+// Locales that exported dates can be formatted in
+var Locales = []string{"en", "id"}
+
+// Defaults returns the settings of a user who never saved any
+func Defaults(userID int64) *model.UserSettings {
+	return &model.UserSettings{
+		UserID:       userID,
+		Timezone:     "UTC",
+		Locale:       "en",
+		WeekStart:    WeekStartMonday,
+		ExportFormat: ExportMarkdown,
+	}
+}
+
+// GetSettings retrieves the settings of a user, falling back to the defaults
+func GetSettings(userID int64) (*model.UserSettings, error) {
+	settings, err := user_settings_repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return Defaults(userID), nil
+	}
+	return settings, nil
+}
+
+// UpdateSettings applies the provided fields on top of the current settings
+func UpdateSettings(userID int64, req *contract.UpdateUserSettingsRequest) (*model.UserSettings, error) {
+	settings, err := GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || timezone == "Local" {
+			return nil, errors.New("timezone must be an IANA timezone such as Asia/Jakarta")
+		}
+		settings.Timezone = timezone
+	}
+	if req.Locale != nil {
+		if !contains(Locales, *req.Locale) {
+			return nil, errors.New("locale must be one of: " + strings.Join(Locales, ", "))
+		}
+		settings.Locale = *req.Locale
+	}
+	if req.WeekStart != nil {
+		if *req.WeekStart != WeekStartMonday && *req.WeekStart != WeekStartSunday {
+			return nil, errors.New("week_start must be monday or sunday")
+		}
+		settings.WeekStart = *req.WeekStart
+	}
+	if req.ExportFormat != nil {
+		if !IsValidExportFormat(*req.ExportFormat) {
+			return nil, errors.New("export_format must be markdown, csv or json")
+		}
+		settings.ExportFormat = *req.ExportFormat
+	}
+	if req.AIProvider != nil {
+		if *req.AIProvider != "" && !llm_service.IsSelectableProvider(*req.AIProvider) {
+			selectable := llm_service.SelectableProviders()
+			if len(selectable) == 0 {
+				return nil, errors.New("ai_provider must be empty, no provider can be picked on this server")
+			}
+			return nil, errors.New("ai_provider must be one of " + strings.Join(selectable, ", ") + ", or empty for the server default")
+		}
+		settings.AIProvider = *req.AIProvider
+	}
+
+	if err := user_settings_repo.Upsert(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// IsValidExportFormat checks whether work logs can be exported in a format
+func IsValidExportFormat(format string) bool {
+	return format == ExportMarkdown || format == ExportCSV || format == ExportJSON
+}
+
+// Location returns the timezone of a user
+func Location(userID int64) (*time.Location, error) {
+	settings, err := GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	return LoadLocation(settings), nil
+}
+
+// LoadLocation resolves the timezone of already loaded settings, UTC when it is unknown
+func LoadLocation(settings *model.UserSettings) *time.Location {
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Today returns the current date of a user as midnight UTC, the way work log dates are handled
+func Today(userID int64) (time.Time, error) {
+	loc, err := Location(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return DateIn(time.Now(), loc), nil
+}
+
+// DateIn returns the calendar date of an instant in a timezone as midnight UTC
+func DateIn(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,423 @@
+package webhook_service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/webhook_repo"
+	"worknote-api/services/background_job_service"
+	"worknote-api/services/event_service"
+)
+
+// JobTypeDeliver is the background job type for webhook deliveries
+const JobTypeDeliver = "webhook.deliver"
+
+// Delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Worknote-Event"
+	HeaderDelivery  = "X-Worknote-Delivery"
+	HeaderTimestamp = "X-Worknote-Timestamp"
+	HeaderSignature = "X-Worknote-Signature"
+)
+
+const deliveryTimeout = 10 * time.Second
+
+// errForbiddenTarget is returned when a webhook URL points at a private or local address
+var errForbiddenTarget = errors.New("webhook url must not point to a private, loopback or link-local address")
+
+// httpClient only connects to public addresses. The check runs on the resolved IP at dial
+// time so that DNS rebinding cannot bypass it, and redirects are not followed.
+var httpClient = &http.Client{
+	Timeout: deliveryTimeout,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: deliveryTimeout,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || isForbiddenIP(ip) {
+					return errForbiddenTarget
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: deliveryTimeout,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// deliveryJobPayload is the payload of a webhook delivery job
+type deliveryJobPayload struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// CreateWebhook creates a new webhook subscription for a user
+func CreateWebhook(userID int64, req *contract.CreateWebhookRequest) (*model.Webhook, error) {
+	if err := validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateEvents(req.Events); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		secret, err = generateSecret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	webhook := &model.Webhook{
+		UserID: userID,
+		URL:    req.URL,
+		Secret: secret,
+		Events: req.Events,
+		Active: active,
+	}
+	if err := webhook_repo.Create(webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// GetWebhook retrieves a webhook by ID for a user
+func GetWebhook(id, userID int64) (*model.Webhook, error) {
+	return webhook_repo.GetByID(id, userID)
+}
+
+// ListWebhooks retrieves all webhooks of a user
+func ListWebhooks(userID int64) ([]model.Webhook, error) {
+	return webhook_repo.ListByUserID(userID)
+}
+
+// UpdateWebhook updates a webhook subscription for a user
+func UpdateWebhook(id, userID int64, req *contract.UpdateWebhookRequest) (*model.Webhook, error) {
+	webhook, err := webhook_repo.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, nil // Not found
+	}
+
+	if req.URL != "" {
+		if err := validateURL(req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = req.URL
+	}
+	if req.Events != nil {
+		if err := validateEvents(req.Events); err != nil {
+			return nil, err
+		}
+		webhook.Events = req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := webhook_repo.Update(webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook subscription for a user
+func DeleteWebhook(id, userID int64) error {
+	err := webhook_repo.Delete(id, userID)
+	if err == sql.ErrNoRows {
+		return nil // Treat as success if not found
+	}
+	return err
+}
+
+// ListDeliveries retrieves the delivery log of a webhook owned by a user
+func ListDeliveries(webhookID, userID int64, limit, offset int) ([]model.WebhookDelivery, error) {
+	webhook, err := webhook_repo.GetByID(webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, nil // Not found
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	deliveries, err := webhook_repo.ListDeliveries(webhookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []model.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+// Redeliver sends the payload of a previous delivery again as a new delivery
+func Redeliver(webhookID, deliveryID, userID int64) (*model.WebhookDelivery, error) {
+	webhook, err := webhook_repo.GetByID(webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, nil // Not found
+	}
+
+	original, err := webhook_repo.GetDeliveryByID(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil || original.WebhookID != webhook.ID {
+		return nil, nil // Not found
+	}
+
+	return queueDelivery(webhook, original.Event, original.Payload)
+}
+
+// HandleEvent is the event_service listener that fans an event out to subscribed webhooks
+func HandleEvent(event *event_service.Event) {
+	webhooks, err := webhook_repo.ListActiveByEvent(event.UserID, event.Type)
+	if err != nil {
+		log.Errorf("failed to list webhooks for %s: %v", event.Type, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("failed to marshal webhook payload for %s: %v", event.Type, err)
+		return
+	}
+
+	for i := range webhooks {
+		if _, err := queueDelivery(&webhooks[i], event.Type, string(payload)); err != nil {
+			log.Errorf("failed to queue webhook %d delivery: %v", webhooks[i].ID, err)
+		}
+	}
+}
+
+// HandleDeliveryJob sends one delivery; returning an error lets the job queue retry with backoff
+func HandleDeliveryJob(ctx context.Context, job *model.BackgroundJob) (interface{}, error) {
+	var payload deliveryJobPayload
+	if err := background_job_service.DecodePayload(job, &payload); err != nil {
+		return nil, err
+	}
+
+	delivery, err := webhook_repo.GetDeliveryByID(payload.DeliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		// The webhook was deleted together with its log
+		return nil, nil
+	}
+
+	webhook, err := webhook_repo.GetByID(delivery.WebhookID, job.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, nil
+	}
+
+	finalAttempt := job.Attempts >= job.MaxAttempts
+
+	if !webhook.Active {
+		delivery.Status = DeliveryStatusFailed
+		delivery.Error = "webhook is inactive"
+		delivery.ResponseStatus = 0
+		if err := webhook_repo.RecordDeliveryAttempt(delivery); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"status": delivery.Status}, nil
+	}
+
+	statusCode, sendErr := send(ctx, webhook, delivery)
+
+	delivery.ResponseStatus = statusCode
+	delivery.Error = ""
+	delivery.DeliveredAt = nil
+	switch {
+	case sendErr == nil:
+		now := time.Now()
+		delivery.Status = DeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+	case finalAttempt:
+		delivery.Status = DeliveryStatusFailed
+		delivery.Error = sendErr.Error()
+	default:
+		delivery.Status = DeliveryStatusPending
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := webhook_repo.RecordDeliveryAttempt(delivery); err != nil {
+		log.Errorf("failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+
+	if sendErr != nil {
+		return nil, sendErr
+	}
+	return map[string]interface{}{"status": delivery.Status, "response_status": statusCode}, nil
+}
+
+// Sign computes the signature of a delivery body: hex(HMAC-SHA256(secret, timestamp + "." + body))
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func queueDelivery(webhook *model.Webhook, event, payload string) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     event,
+		Payload:   payload,
+	}
+	if err := webhook_repo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	if _, err := background_job_service.Enqueue(webhook.UserID, JobTypeDeliver, deliveryJobPayload{DeliveryID: delivery.ID}); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+func send(ctx context.Context, webhook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "worknote-api-webhooks")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(webhook.Secret, timestamp, body))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if errors.Is(err, errForbiddenTarget) {
+			return 0, errForbiddenTarget
+		}
+		return 0, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Only the status is kept, the body is drained so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return errors.New("url is required")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.New("url must be an absolute http or https URL")
+	}
+
+	// Obvious local targets are refused early, hostnames are checked again when delivering
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errForbiddenTarget
+	}
+	if ip := net.ParseIP(host); ip != nil && isForbiddenIP(ip) {
+		return errForbiddenTarget
+	}
+	return nil
+}
+
+// isForbiddenIP reports whether an address is not reachable on the public internet
+func isForbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || sharedAddressSpace.Contains(ip) || ip.To4() != nil && ip.To4()[0] == 0
+}
+
+func validateEvents(events []string) error {
+	if len(events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, event := range events {
+		if event != "*" && !event_service.IsValidEventType(event) {
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -1,6 +1,9 @@
 package work_log_download_service
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"regexp"
 	"strings"
@@ -8,14 +11,38 @@ import (
 
 	"worknote-api/model"
 	"worknote-api/repos/work_log_repo"
+	"worknote-api/services/project_service"
+	"worknote-api/services/user_settings_service"
+	"worknote-api/utils/locale"
 )
 
 var dateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
 
+// contentTypes maps an export format to its content type and file extension
+var contentTypes = map[string][2]string{
+	user_settings_service.ExportMarkdown: {"text/markdown; charset=utf-8", ".md"},
+	user_settings_service.ExportCSV:      {"text/csv; charset=utf-8", ".csv"},
+	user_settings_service.ExportJSON:     {"application/json", ".json"},
+}
+
+// Download is a rendered export of work logs
+type Download struct {
+	Content     string
+	Filename    string
+	ContentType string
+}
+
+type jsonWorkLog struct {
+	Date    string `json:"date"`
+	Content string `json:"content"`
+}
+
 // DownloadRequest represents the request parameters for downloading worklogs
 type DownloadRequest struct {
 	StartDate string
 	EndDate   string
+	ProjectID int64  // Optional, only export the bullets of this project
+	Format    string // markdown, csv or json; defaults to the user's export format
 }
 
 // Validate validates the download request
@@ -35,28 +62,66 @@ func (r *DownloadRequest) Validate() error {
 	if r.StartDate > r.EndDate {
 		return errors.New("start_date must be before or equal to end_date")
 	}
+	if r.Format != "" && !user_settings_service.IsValidExportFormat(r.Format) {
+		return errors.New("format must be markdown, csv or json")
+	}
 	return nil
 }
 
-// DownloadWorkLogs retrieves worklogs within a date range and generates markdown
-func DownloadWorkLogs(userID int64, req *DownloadRequest) (string, string, error) {
+// DownloadWorkLogs retrieves worklogs within a date range and renders them in the requested
+// format, dates written in the user's locale
+func DownloadWorkLogs(userID int64, req *DownloadRequest) (*Download, error) {
 	if err := req.Validate(); err != nil {
-		return "", "", err
+		return nil, err
+	}
+
+	settings, err := user_settings_service.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+	format := req.Format
+	if format == "" {
+		format = settings.ExportFormat
+	}
+
+	project, err := project_service.ResolveProject(userID, req.ProjectID)
+	if err != nil {
+		return nil, err
 	}
 
 	logs, err := work_log_repo.ListByUserIDAndDateRange(userID, req.StartDate, req.EndDate)
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+	logs = project_service.FilterWorkLogs(logs, project)
+
+	var content string
+	switch format {
+	case user_settings_service.ExportCSV:
+		content, err = GenerateCSV(logs)
+	case user_settings_service.ExportJSON:
+		content, err = GenerateJSON(logs)
+	default:
+		content = GenerateLocalizedMarkdown(logs, settings.Locale)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	markdown := GenerateMarkdown(logs)
-	filename := GenerateFilename(req.StartDate, req.EndDate)
+	filename := "worklog-" + req.StartDate + "-to-" + req.EndDate
+	if project != nil {
+		filename = "worklog-" + project.Tag + "-" + req.StartDate + "-to-" + req.EndDate
+	}
 
-	return markdown, filename, nil
+	return &Download{
+		Content:     content,
+		Filename:    filename + contentTypes[format][1],
+		ContentType: contentTypes[format][0],
+	}, nil
 }
 
-// GenerateMarkdown converts worklogs to markdown format
-func GenerateMarkdown(logs []model.WorkLog) string {
+// GenerateLocalizedMarkdown converts worklogs to markdown format with dates in a locale
+func GenerateLocalizedMarkdown(logs []model.WorkLog, loc string) string {
 	if len(logs) == 0 {
 		return ""
 	}
@@ -86,7 +151,7 @@ func GenerateMarkdown(logs []model.WorkLog) string {
 			sb.WriteString(log.Date)
 			sb.WriteString("\n")
 		} else {
-			sb.WriteString(parsedDate.Format("Mon, 2 January 2006"))
+			sb.WriteString(locale.FormatDate(parsedDate, loc))
 			sb.WriteString("\n")
 		}
 
@@ -112,8 +177,38 @@ func GenerateMarkdown(logs []model.WorkLog) string {
 	return sb.String()
 }
 
-// GenerateFilename creates a filename for the download
-func GenerateFilename(startDate, endDate string) string {
-	// Format: worklog-2024-01-01-to-2024-01-31.md
-	return "worklog-" + startDate + "-to-" + endDate + ".md"
+// GenerateCSV converts worklogs to CSV with one row per day
+func GenerateCSV(logs []model.WorkLog) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{{"date", "content"}}
+	for _, log := range logs {
+		records = append(records, []string{displayDate(log.Date), log.Content})
+	}
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// GenerateJSON converts worklogs to a JSON array
+func GenerateJSON(logs []model.WorkLog) (string, error) {
+	entries := make([]jsonWorkLog, len(logs))
+	for i, log := range logs {
+		entries[i] = jsonWorkLog{Date: displayDate(log.Date), Content: log.Content}
+	}
+	body, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// displayDate trims a stored date to YYYY-MM-DD
+func displayDate(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
 }
@@ -1,12 +1,16 @@
 package work_log_import_service
 
 import (
+	"context"
 	"errors"
 	"regexp"
 	"strings"
 	"time"
 
+	"worknote-api/model"
 	"worknote-api/repos/work_log_repo"
+	"worknote-api/services/background_job_service"
+	"worknote-api/services/work_log_service"
 )
 
 var (
@@ -14,6 +18,14 @@ var (
 	dateLineRegex = regexp.MustCompile(`^[A-Za-z]+,\s*\d{1,2}\s+[A-Za-z]+\s+\d{4}$`)
 )
 
+// JobTypeImport is the background job type for markdown imports
+const JobTypeImport = "work_log.import"
+
+// importJobPayload is the payload of a markdown import job
+type importJobPayload struct {
+	Content string `json:"content"`
+}
+
 // ImportedWorkLog represents a worklog parsed from markdown
 type ImportedWorkLog struct {
 	Date    string
@@ -133,7 +145,7 @@ func ImportWorkLogs(userID int64, worklogs []ImportedWorkLog) (*ImportResult, er
 
 		if existing != nil {
 			// Update existing
-			_, err = work_log_repo.Upsert(userID, wl.Date, wl.Content)
+			_, err = work_log_service.SaveWorkLog(userID, wl.Date, wl.Content)
 			if err != nil {
 				result.Errors = append(result.Errors, "error updating "+wl.Date+": "+err.Error())
 			} else {
@@ -141,7 +153,7 @@ func ImportWorkLogs(userID int64, worklogs []ImportedWorkLog) (*ImportResult, er
 			}
 		} else {
 			// Create new
-			_, err = work_log_repo.Upsert(userID, wl.Date, wl.Content)
+			_, err = work_log_service.SaveWorkLog(userID, wl.Date, wl.Content)
 			if err != nil {
 				result.Errors = append(result.Errors, "error creating "+wl.Date+": "+err.Error())
 			} else {
@@ -162,3 +174,21 @@ func ImportFromMarkdown(userID int64, markdownContent string) (*ImportResult, er
 
 	return ImportWorkLogs(userID, worklogs)
 }
+
+// EnqueueImportFromMarkdown validates the markdown and queues the import as a background job
+func EnqueueImportFromMarkdown(userID int64, markdownContent string) (*model.BackgroundJob, error) {
+	// Parse upfront so malformed files are rejected synchronously
+	if _, err := ParseMarkdown(markdownContent); err != nil {
+		return nil, err
+	}
+	return background_job_service.Enqueue(userID, JobTypeImport, importJobPayload{Content: markdownContent})
+}
+
+// HandleImportJob runs a queued markdown import job
+func HandleImportJob(ctx context.Context, job *model.BackgroundJob) (interface{}, error) {
+	var payload importJobPayload
+	if err := background_job_service.DecodePayload(job, &payload); err != nil {
+		return nil, err
+	}
+	return ImportFromMarkdown(job.UserID, payload.Content)
+}
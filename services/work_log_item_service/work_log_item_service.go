@@ -0,0 +1,227 @@
+package work_log_item_service
+
+import (
+	"regexp"
+	"strings"
+
+	"worknote-api/model"
+	"worknote-api/repos/project_repo"
+	"worknote-api/repos/work_log_item_repo"
+	"worknote-api/services/work_log_tag_service"
+)
+
+// Item statuses
+const (
+	StatusDone       = "done"
+	StatusInProgress = "in-progress"
+	StatusBlocked    = "blocked"
+)
+
+var (
+	// Bullet markers stripped from a line, keep in sync with migration 012
+	bulletRegex = regexp.MustCompile(`^[-*•]\s+`)
+	// Status markers prefixed to a bullet, e.g. "- [blocked] waiting on review"
+	statusRegex = regexp.MustCompile(`(?i)^\[(done|in-progress|wip|blocked)\]\s*`)
+)
+
+// IsValidStatus reports whether status is a known item status
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusDone, StatusInProgress, StatusBlocked:
+		return true
+	}
+	return false
+}
+
+// ParseItems splits a free-form content into items, one per non-empty line.
+// Bullet markers are dropped and a leading [in-progress] or [blocked] sets the status.
+func ParseItems(content string) []model.WorkLogItem {
+	var items []model.WorkLogItem
+	for _, line := range strings.Split(content, "\n") {
+		text := bulletRegex.ReplaceAllString(strings.TrimSpace(line), "")
+
+		status := StatusDone
+		if match := statusRegex.FindStringSubmatch(text); match != nil {
+			switch strings.ToLower(match[1]) {
+			case "in-progress", "wip":
+				status = StatusInProgress
+			case "blocked":
+				status = StatusBlocked
+			}
+			text = text[len(match[0]):]
+		}
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		items = append(items, model.WorkLogItem{Text: text, Status: status})
+	}
+	return items
+}
+
+// RenderContent renders items as the markdown bullet list stored in the work log content
+func RenderContent(items []model.WorkLogItem) string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = renderLine(item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderLine(item model.WorkLogItem) string {
+	prefix := "- "
+	if item.Status != StatusDone && item.Status != "" {
+		prefix += "[" + item.Status + "] "
+	}
+	return prefix + item.Text
+}
+
+// MergeContent writes edited items back into content, whose lines previous was parsed from.
+// Only the lines of changed items are rewritten: unchanged items keep their original line,
+// headings and bullet style included, and blank lines stay where they are. Items beyond
+// the lines of content are added after the last one. Content that no longer lines up with
+// previous is rendered from scratch.
+func MergeContent(content string, previous, items []model.WorkLogItem) string {
+	lines := strings.Split(content, "\n")
+
+	// The lines each previous item was read from, in order
+	var slots []int
+	for i, line := range lines {
+		parsed := ParseItems(line)
+		if len(parsed) == 0 {
+			continue
+		}
+		k := len(slots)
+		if k >= len(previous) || parsed[0].Text != previous[k].Text || parsed[0].Status != previous[k].Status {
+			return RenderContent(items)
+		}
+		slots = append(slots, i)
+	}
+	if len(slots) == 0 || len(slots) != len(previous) {
+		return RenderContent(items)
+	}
+
+	original := make(map[int64]int, len(previous))
+	for k, item := range previous {
+		original[item.ID] = k
+	}
+	line := func(item model.WorkLogItem) string {
+		if k, ok := original[item.ID]; ok && item.ID != 0 &&
+			previous[k].Text == item.Text && previous[k].Status == item.Status {
+			return lines[slots[k]]
+		}
+		return renderLine(item)
+	}
+
+	merged := make([]string, 0, len(lines)+len(items))
+	next, slot := 0, 0
+	for i, text := range lines {
+		if slot < len(slots) && slots[slot] == i {
+			slot++
+			if next < len(items) {
+				merged = append(merged, line(items[next]))
+				next++
+			}
+			if slot == len(slots) {
+				for ; next < len(items); next++ {
+					merged = append(merged, line(items[next]))
+				}
+			}
+			continue
+		}
+		merged = append(merged, text)
+	}
+	// Blank lines left behind by removed trailing items
+	return strings.TrimRight(strings.Join(merged, "\n"), "\n")
+}
+
+// ListItems retrieves the items of a work log in order
+func ListItems(workLogID int64) ([]model.WorkLogItem, error) {
+	items, err := work_log_item_repo.ListByWorkLogID(workLogID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []model.WorkLogItem{}
+	}
+	return items, nil
+}
+
+// SyncItems re-derives the items of a work log saved as free-form content.
+// Lines matching an existing item keep its identity, project and tracked time;
+// other lines are attributed to the project whose #tag they carry.
+func SyncItems(workLog *model.WorkLog) error {
+	existing, err := work_log_item_repo.ListByWorkLogID(workLog.ID)
+	if err != nil {
+		return err
+	}
+
+	projects, err := project_repo.ListByUserID(workLog.UserID, true)
+	if err != nil {
+		return err
+	}
+	projectIDs := make(map[string]int64, len(projects))
+	for _, project := range projects {
+		projectIDs[project.Tag] = project.ID
+	}
+
+	used := make([]bool, len(existing))
+	items := ParseItems(workLog.Content)
+	for i := range items {
+		item := &items[i]
+		for j, old := range existing {
+			if !used[j] && old.Text == item.Text {
+				used[j] = true
+				item.ID = old.ID
+				item.ProjectID = old.ProjectID
+				item.DurationMinutes = old.DurationMinutes
+				item.StartedAt = old.StartedAt
+				item.EndedAt = old.EndedAt
+				break
+			}
+		}
+		if item.ProjectID == 0 {
+			item.ProjectID = ProjectIDFromTags(item.Text, projectIDs)
+		}
+	}
+
+	return work_log_item_repo.ReplaceForWorkLog(workLog.ID, workLog.UserID, items)
+}
+
+// ProjectIDFromTags returns the project whose #tag a text carries, or zero
+func ProjectIDFromTags(text string, projectIDs map[string]int64) int64 {
+	for _, tag := range work_log_tag_service.ParseTags(text) {
+		if tag.Kind != work_log_tag_service.KindTag {
+			continue
+		}
+		if id, ok := projectIDs[tag.Name]; ok {
+			return id
+		}
+	}
+	return 0
+}
+
+// ParseItemText normalizes the text of a single bullet the way ParseItems would read it back.
+// Returns false when the text is empty or spans several lines.
+func ParseItemText(text string) (model.WorkLogItem, bool) {
+	items := ParseItems(text)
+	if len(items) != 1 || strings.Contains(strings.TrimSpace(text), "\n") {
+		return model.WorkLogItem{}, false
+	}
+	return items[0], true
+}
+
+// SwapProjectTag removes the #tag of the previous project from a text and appends the
+// #tag of the next one, so tag-based attribution follows the item's project
+func SwapProjectTag(text string, previous, next *model.Project) string {
+	if previous != nil && (next == nil || previous.ID != next.ID) {
+		tagToken := regexp.MustCompile(`(?i)(^|\s)#` + regexp.QuoteMeta(previous.Tag) + `($|[^A-Za-z0-9_-])`)
+		text = strings.TrimSpace(tagToken.ReplaceAllString(text, "$1$2"))
+		text = strings.Join(strings.Fields(text), " ")
+	}
+	if next != nil && ProjectIDFromTags(text, map[string]int64{next.Tag: next.ID}) != next.ID {
+		text += " #" + next.Tag
+	}
+	return text
+}
@@ -0,0 +1,94 @@
+package work_log_revision_service
+
+import (
+	"errors"
+	"regexp"
+
+	"worknote-api/model"
+	"worknote-api/repos/work_log_repo"
+	"worknote-api/repos/work_log_revision_repo"
+	"worknote-api/services/work_log_service"
+	"worknote-api/utils/diff"
+)
+
+var dateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// ListRevisions retrieves the prior versions of a day, newest first
+func ListRevisions(userID int64, date string, limit, offset int) ([]model.WorkLogRevision, error) {
+	if !dateRegex.MatchString(date) {
+		return nil, errors.New("date must be in YYYY-MM-DD format")
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	revisions, err := work_log_revision_repo.ListByDate(userID, date, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []model.WorkLogRevision{}
+	}
+	return revisions, nil
+}
+
+// GetRevision retrieves one prior version of a day
+func GetRevision(userID int64, date string, id int64) (*model.WorkLogRevision, error) {
+	if !dateRegex.MatchString(date) {
+		return nil, errors.New("date must be in YYYY-MM-DD format")
+	}
+	return work_log_revision_repo.GetByID(id, userID, date)
+}
+
+// DiffRevisions compares two versions of a day line by line.
+// A zero toID compares against the current content. Returns diff.ErrTooLarge when
+// the versions differ too much to be compared.
+func DiffRevisions(userID int64, date string, fromID, toID int64) ([]diff.Line, error) {
+	from, err := GetRevision(userID, date, fromID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, nil // Not found
+	}
+
+	var toContent string
+	if toID != 0 {
+		to, err := GetRevision(userID, date, toID)
+		if err != nil {
+			return nil, err
+		}
+		if to == nil {
+			return nil, nil // Not found
+		}
+		toContent = to.Content
+	} else {
+		current, err := work_log_repo.GetByDate(userID, date)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil {
+			toContent = current.Content
+		}
+	}
+
+	return diff.Lines(from.Content, toContent)
+}
+
+// RestoreRevision makes a prior version the current content of a day
+func RestoreRevision(userID int64, date string, id int64) (*model.WorkLog, error) {
+	revision, err := GetRevision(userID, date, id)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, nil // Not found
+	}
+	return work_log_service.RestoreWorkLog(userID, date, revision.Content)
+}
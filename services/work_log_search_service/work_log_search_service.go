@@ -0,0 +1,171 @@
+package work_log_search_service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/work_log_repo"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxQueryLength     = 256
+)
+
+var dateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// SearchWorkLogs runs a ranked full-text search over a user's work logs
+func SearchWorkLogs(userID int64, req *contract.SearchWorkLogsRequest) ([]model.WorkLogSearchResult, int, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, 0, errors.New("q is required")
+	}
+	if len(req.Query) > maxQueryLength {
+		return nil, 0, errors.New("q is too long")
+	}
+	if req.From != "" && !dateRegex.MatchString(req.From) {
+		return nil, 0, errors.New("from must be in YYYY-MM-DD format")
+	}
+	if req.To != "" && !dateRegex.MatchString(req.To) {
+		return nil, 0, errors.New("to must be in YYYY-MM-DD format")
+	}
+
+	tsQuery := BuildTSQuery(req.Query)
+	if tsQuery == "" {
+		return nil, 0, errors.New("q must contain at least one searchable word")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	return work_log_repo.Search(userID, work_log_repo.SearchFilter{
+		TSQuery: tsQuery,
+		From:    req.From,
+		To:      req.To,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+// BuildTSQuery converts a user search string into a safe to_tsquery expression.
+//
+//	billing migration    -> billing & migration
+//	"billing migration"  -> billing <-> migration   (phrase)
+//	migrat*              -> migrat:*                (prefix)
+//	-staging             -> !staging                (exclude)
+//	billing OR invoices  -> billing | invoices
+//
+// Every lexeme is reduced to letters and digits so user input can never produce
+// a tsquery syntax error.
+func BuildTSQuery(q string) string {
+	var parts []string
+	pendingOr, pendingNot := false, false
+
+	for _, term := range tokenize(q) {
+		if !term.quoted && term.text == "OR" {
+			pendingOr = len(parts) > 0
+			continue
+		}
+		// A lone "-" negates the following token, as in -"old stuff"
+		if !term.quoted && term.text == "-" {
+			pendingNot = true
+			continue
+		}
+
+		negate := pendingNot
+		pendingNot = false
+		text := term.text
+		if !term.quoted && strings.HasPrefix(text, "-") {
+			negate = true
+			text = strings.TrimLeft(text, "-")
+		}
+
+		prefix := false
+		if strings.HasSuffix(text, "*") {
+			prefix = true
+			text = strings.TrimRight(text, "*")
+		}
+
+		words := splitWords(text)
+		if len(words) == 0 {
+			continue
+		}
+		if prefix {
+			words[len(words)-1] += ":*"
+		}
+
+		expr := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			expr = "(" + expr + ")"
+		}
+		if negate {
+			expr = "!" + expr
+		}
+
+		if len(parts) > 0 {
+			if pendingOr {
+				parts = append(parts, "|")
+			} else {
+				parts = append(parts, "&")
+			}
+		}
+		parts = append(parts, expr)
+		pendingOr = false
+	}
+
+	return strings.Join(parts, " ")
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// tokenize splits on whitespace while keeping "quoted phrases" together
+func tokenize(q string) []token {
+	var tokens []token
+	var current strings.Builder
+	inQuotes := false
+
+	flush := func(quoted bool) {
+		if current.Len() > 0 {
+			tokens = append(tokens, token{text: current.String(), quoted: quoted})
+			current.Reset()
+		}
+	}
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			flush(inQuotes)
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			flush(false)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush(inQuotes)
+
+	return tokens
+}
+
+// splitWords keeps only letters and digits, splitting on anything else
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -1,13 +1,87 @@
 package work_log_service
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
 
+	log "github.com/sirupsen/logrus"
+
+	"worknote-api/config"
 	"worknote-api/contract"
+	"worknote-api/datastore"
 	"worknote-api/model"
+	"worknote-api/repos/cache_repo"
+	"worknote-api/repos/project_repo"
+	"worknote-api/repos/work_log_item_repo"
 	"worknote-api/repos/work_log_repo"
+	"worknote-api/repos/work_log_revision_repo"
+	"worknote-api/services/event_service"
+	"worknote-api/services/user_settings_service"
+	"worknote-api/services/work_log_item_service"
+	"worknote-api/services/work_log_tag_service"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 366
+)
+
+// Reasons recorded on the revision holding the content a change replaced
+const (
+	RevisionReasonUpdate  = "update"
+	RevisionReasonDelete  = "delete"
+	RevisionReasonRestore = "restore"
+)
+
+var (
+	// ErrWorkLogTrashed is returned when writing to a day whose work log is in the trash
+	ErrWorkLogTrashed = errors.New("the work log of this date is in the trash, restore or purge it first")
+	// ErrWorkLogExists is returned when creating the log of a day that already has content
+	ErrWorkLogExists = errors.New("a work log already exists for this date")
 )
 
+var dateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// relativeDateRegex matches "-N", N days before today
+var relativeDateRegex = regexp.MustCompile(`^-(\d{1,4})$`)
+
+// WorkLogPage is one page of a work log listing
+type WorkLogPage struct {
+	Logs       []model.WorkLog
+	Total      int
+	NextCursor string
+}
+
+// ResolveDate turns "today", "yesterday" and "-N" into a YYYY-MM-DD date in the user's
+// timezone. Other values are returned as is.
+func ResolveDate(userID int64, date string) (string, error) {
+	daysAgo := -1
+	switch date {
+	case "today":
+		daysAgo = 0
+	case "yesterday":
+		daysAgo = 1
+	default:
+		if match := relativeDateRegex.FindStringSubmatch(date); match != nil {
+			daysAgo, _ = strconv.Atoi(match[1])
+		}
+	}
+	if daysAgo < 0 {
+		return date, nil
+	}
+
+	today, err := user_settings_service.Today(userID)
+	if err != nil {
+		return "", err
+	}
+	return today.AddDate(0, 0, -daysAgo).Format("2006-01-02"), nil
+}
+
 // UpsertWorkLog creates or updates a work log entry for a user
 func UpsertWorkLog(userID int64, req *contract.UpsertWorkLogRequest) (*model.WorkLog, error) {
 	if req.Date == "" {
@@ -16,6 +90,11 @@ func UpsertWorkLog(userID int64, req *contract.UpsertWorkLogRequest) (*model.Wor
 	if req.Content == "" {
 		return nil, errors.New("content is required")
 	}
+	date, err := ResolveDate(userID, req.Date)
+	if err != nil {
+		return nil, err
+	}
+	req.Date = date
 
 	content := req.Content
 
@@ -30,26 +109,699 @@ func UpsertWorkLog(userID int64, req *contract.UpsertWorkLogRequest) (*model.Wor
 		}
 	}
 
-	return work_log_repo.Upsert(userID, req.Date, content)
+	return SaveWorkLog(userID, req.Date, content)
+}
+
+// SaveWorkLog persists the content of a day and invalidates the cached reads for it.
+// Every writer of work logs should go through here so the cache stays consistent.
+// Returns ErrWorkLogTrashed when the day is in the trash.
+func SaveWorkLog(userID int64, date, content string) (*model.WorkLog, error) {
+	return saveWorkLog(userID, date, content, RevisionReasonUpdate)
+}
+
+// CreateWorkLog saves the content of a day only if it has none yet. The check is part of
+// the write, so content saved concurrently is never overwritten.
+// Returns ErrWorkLogExists when the day has content and ErrWorkLogTrashed when it is in the trash.
+func CreateWorkLog(userID int64, date, content string) (*model.WorkLog, error) {
+	workLog, err := work_log_repo.InsertIfEmpty(userID, date, content)
+	if err != nil {
+		return nil, err
+	}
+	if workLog == nil {
+		existing, err := work_log_repo.GetByDate(userID, date)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrWorkLogExists
+		}
+		return nil, ErrWorkLogTrashed
+	}
+
+	if err := work_log_item_service.SyncItems(workLog); err != nil {
+		log.Errorf("failed to sync items of work log %d: %v", workLog.ID, err)
+	}
+	workLogSaved(workLog)
+
+	return workLog, nil
+}
+
+// RestoreWorkLog brings back a previous content of a day, keeping the current one as a revision
+func RestoreWorkLog(userID int64, date, content string) (*model.WorkLog, error) {
+	return saveWorkLog(userID, date, content, RevisionReasonRestore)
+}
+
+// saveWorkLog persists a day and re-derives its items from the content. The day stays
+// locked while its revision is recorded and the content written, so concurrent saves
+// each keep the content they replaced.
+func saveWorkLog(userID int64, date, content, reason string) (*model.WorkLog, error) {
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := work_log_repo.LockByDate(tx, userID, date)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.DeletedAt != nil {
+		return nil, ErrWorkLogTrashed
+	}
+	if current != nil && current.Content != content {
+		if err := work_log_revision_repo.CreateTx(tx, newRevision(current, reason)); err != nil {
+			return nil, err
+		}
+	}
+
+	workLog, err := work_log_repo.UpsertTx(tx, userID, date, content)
+	if err != nil {
+		return nil, err
+	}
+	if workLog == nil {
+		return nil, ErrWorkLogTrashed
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if err := work_log_item_service.SyncItems(workLog); err != nil {
+		log.Errorf("failed to sync items of work log %d: %v", workLog.ID, err)
+	}
+	workLogSaved(workLog)
+
+	return workLog, nil
+}
+
+// workLogSaved runs what follows every save of a day: tag sync, cache invalidation and the event.
+// The cache is dropped once the tags are synced, so tag-filtered lists cannot keep the old tags.
+func workLogSaved(workLog *model.WorkLog) {
+	if err := work_log_tag_service.SyncTags(workLog); err != nil {
+		log.Errorf("failed to sync tags of work log %d: %v", workLog.ID, err)
+	}
+
+	InvalidateCache(workLog.UserID, workLog.Date)
+
+	event_service.Publish(workLog.UserID, event_service.EventWorkLogUpserted, contract.WorkLogEventData{
+		ID:      workLog.ID,
+		Date:    workLog.Date,
+		Content: workLog.Content,
+	})
+}
+
+// workLogDeleted runs what follows the move of a day to the trash
+func workLogDeleted(userID int64, date string) {
+	InvalidateCache(userID, date)
+
+	event_service.Publish(userID, event_service.EventWorkLogDeleted, contract.WorkLogDeletedEventData{
+		Date: date,
+	})
 }
 
 // GetWorkLogByDate retrieves a work log by user ID and date
-func GetWorkLogByDate(userID int64, date string) (*model.WorkLog, error) {
+func GetWorkLogByDate(userID int64, date string, bypassCache bool) (*model.WorkLog, error) {
 	if date == "" {
 		return nil, errors.New("date is required")
 	}
-	return work_log_repo.GetByDate(userID, date)
+	date, err := ResolveDate(userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	key := workLogDateKey(userID, date)
+	if !bypassCache && cacheEnabled() {
+		var cached model.WorkLog
+		hit, err := cache_repo.GetJSON(key, &cached)
+		if err != nil {
+			log.Warnf("work log cache read failed: %v", err)
+		} else if hit {
+			return &cached, nil
+		}
+	}
+
+	workLog, err := work_log_repo.GetByDate(userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// Missing days are not cached so a freshly created log is never hidden
+	if workLog != nil && cacheEnabled() {
+		if err := cache_repo.SetJSON(key, workLog, config.Get().CacheWorkLogTTL); err != nil {
+			log.Warnf("work log cache write failed: %v", err)
+		}
+	}
+
+	return workLog, nil
+}
+
+// ListWorkLogs retrieves a page of work logs for a user, optionally within a date range.
+// Pagination is keyset based on the date; the cursor is opaque to clients.
+func ListWorkLogs(userID int64, req *contract.ListWorkLogsRequest, bypassCache bool) (*WorkLogPage, error) {
+	filter, err := buildListFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := workLogListKey(userID, filter)
+	if !bypassCache && cacheEnabled() {
+		var cached WorkLogPage
+		hit, err := cache_repo.GetJSON(key, &cached)
+		if err != nil {
+			log.Warnf("work log cache read failed: %v", err)
+		} else if hit {
+			return &cached, nil
+		}
+	}
+
+	// Fetch one extra row to know whether another page exists
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	logs, total, err := work_log_repo.List(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &WorkLogPage{Logs: logs, Total: total}
+	if len(logs) > limit {
+		page.Logs = logs[:limit]
+		page.NextCursor = encodeCursor(page.Logs[limit-1].Date)
+	}
+	if page.Logs == nil {
+		page.Logs = []model.WorkLog{}
+	}
+
+	if cacheEnabled() {
+		if err := cache_repo.SetTrackedJSON(workLogListIndexKey(userID), key, page, config.Get().CacheWorkLogTTL); err != nil {
+			log.Warnf("work log cache write failed: %v", err)
+		}
+	}
+
+	return page, nil
 }
 
-// ListWorkLogs retrieves all work logs for a user
-func ListWorkLogs(userID int64) ([]model.WorkLog, error) {
-	return work_log_repo.ListByUserID(userID)
+// Validation errors of the list query parameters
+var (
+	ErrInvalidFrom   = errors.New("from must be in YYYY-MM-DD format")
+	ErrInvalidTo     = errors.New("to must be in YYYY-MM-DD format")
+	ErrInvalidRange  = errors.New("from must be before or equal to to")
+	ErrInvalidOrder  = errors.New("order must be asc or desc")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// IsListQueryError reports whether ListWorkLogs failed on its query parameters
+func IsListQueryError(err error) bool {
+	switch err {
+	case ErrInvalidFrom, ErrInvalidTo, ErrInvalidRange, ErrInvalidOrder, ErrInvalidCursor:
+		return true
+	}
+	return false
 }
 
-// DeleteWorkLogByDate deletes a work log by user ID and date
+// buildListFilter validates list query parameters into a repository filter
+func buildListFilter(req *contract.ListWorkLogsRequest) (work_log_repo.ListFilter, error) {
+	filter := work_log_repo.ListFilter{
+		From:  req.From,
+		To:    req.To,
+		Limit: req.Limit,
+	}
+
+	if filter.From != "" && !dateRegex.MatchString(filter.From) {
+		return filter, ErrInvalidFrom
+	}
+	if filter.To != "" && !dateRegex.MatchString(filter.To) {
+		return filter, ErrInvalidTo
+	}
+	if filter.From != "" && filter.To != "" && filter.From > filter.To {
+		return filter, ErrInvalidRange
+	}
+
+	switch req.Order {
+	case "", "desc":
+	case "asc":
+		filter.Asc = true
+	default:
+		return filter, ErrInvalidOrder
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+
+	if req.Tag != "" {
+		filter.TagKind, filter.TagName = work_log_tag_service.ParseTagFilter(req.Tag)
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = after
+	}
+
+	return filter, nil
+}
+
+// encodeCursor turns the date of the last returned log into an opaque cursor
+func encodeCursor(date string) string {
+	// Dates come back from the database as timestamps; the day is enough for the keyset
+	if len(date) > 10 {
+		date = date[:10]
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(date))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !dateRegex.Match(decoded) {
+		return "", ErrInvalidCursor
+	}
+	return string(decoded), nil
+}
+
+// DeleteWorkLogByDate moves the work log of a day to the trash
 func DeleteWorkLogByDate(userID int64, date string) error {
 	if date == "" {
 		return errors.New("date is required")
 	}
-	return work_log_repo.DeleteByDate(userID, date)
+	date, err := ResolveDate(userID, date)
+	if err != nil {
+		return err
+	}
+
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current, err := work_log_repo.LockByDate(tx, userID, date)
+	if err != nil {
+		return err
+	}
+	// Nothing to delete, rolling back drops the row reserved by the lock
+	if current == nil || current.DeletedAt != nil {
+		return nil
+	}
+	if err := work_log_revision_repo.CreateTx(tx, newRevision(current, RevisionReasonDelete)); err != nil {
+		return err
+	}
+	if err := work_log_repo.DeleteByDateTx(tx, userID, date); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	workLogDeleted(userID, date)
+
+	return nil
+}
+
+// ListWorkLogItems retrieves the bullets of a day along with the rendered content
+func ListWorkLogItems(userID int64, date string) ([]model.WorkLogItem, string, error) {
+	date, err := ResolveDate(userID, date)
+	if err != nil {
+		return nil, "", err
+	}
+	if !dateRegex.MatchString(date) {
+		return nil, "", errors.New("date must be in YYYY-MM-DD format")
+	}
+
+	workLog, err := work_log_repo.GetByDate(userID, date)
+	if err != nil {
+		return nil, "", err
+	}
+	if workLog == nil {
+		return []model.WorkLogItem{}, "", nil
+	}
+
+	items, err := work_log_item_service.ListItems(workLog.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return items, workLog.Content, nil
+}
+
+// AddWorkLogItem adds a bullet to a day, creating the work log when needed
+func AddWorkLogItem(userID int64, date string, req *contract.CreateWorkLogItemRequest) (*model.WorkLogItem, error) {
+	date, err := ResolveDate(userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := work_log_item_service.ParseItemText(req.Text)
+	if !ok {
+		return nil, errors.New("text must be a single non-empty line")
+	}
+	if req.Status != "" {
+		item.Status = req.Status
+	}
+	if !work_log_item_service.IsValidStatus(item.Status) {
+		return nil, errors.New("status must be done, in-progress or blocked")
+	}
+	if req.DurationMinutes < 0 {
+		return nil, errors.New("duration_minutes must not be negative")
+	}
+	item.DurationMinutes = req.DurationMinutes
+
+	item.StartedAt, item.EndedAt, err = parseItemTimes(req.StartedAt, req.EndedAt)
+	if err != nil {
+		return nil, err
+	}
+	if item.DurationMinutes == 0 {
+		item.DurationMinutes = trackedMinutes(item.StartedAt, item.EndedAt)
+	}
+
+	if err := assignItemProject(userID, &item, req.ProjectID); err != nil {
+		return nil, err
+	}
+
+	position := 0
+	items, _, err := editItems(userID, date, func(items []model.WorkLogItem) ([]model.WorkLogItem, bool, error) {
+		position = len(items)
+		if req.Position != nil && *req.Position >= 0 && *req.Position < position {
+			position = *req.Position
+		}
+		items = append(items, model.WorkLogItem{})
+		copy(items[position+1:], items[position:])
+		items[position] = item
+		return items, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &items[position], nil
+}
+
+// UpdateWorkLogItem edits a bullet of a day
+func UpdateWorkLogItem(userID int64, date string, itemID int64, req *contract.UpdateWorkLogItemRequest) (*model.WorkLogItem, error) {
+	date, err := ResolveDate(userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	items, _, err := editItems(userID, date, func(items []model.WorkLogItem) ([]model.WorkLogItem, bool, error) {
+		index = findItem(items, itemID)
+		if index < 0 {
+			return nil, false, nil // Not found
+		}
+		if err := applyItemUpdate(userID, &items[index], req); err != nil {
+			return nil, false, err
+		}
+		return items, true, nil
+	})
+	if err != nil || index < 0 {
+		return nil, err
+	}
+	return &items[index], nil
+}
+
+// applyItemUpdate validates and applies the fields of an update request to an item
+func applyItemUpdate(userID int64, item *model.WorkLogItem, req *contract.UpdateWorkLogItemRequest) error {
+	var err error
+	if req.Text != nil {
+		parsed, ok := work_log_item_service.ParseItemText(*req.Text)
+		if !ok {
+			return errors.New("text must be a single non-empty line")
+		}
+		item.Text = parsed.Text
+		if req.Status == nil {
+			item.Status = parsed.Status
+		}
+	}
+	if req.Status != nil {
+		if !work_log_item_service.IsValidStatus(*req.Status) {
+			return errors.New("status must be done, in-progress or blocked")
+		}
+		item.Status = *req.Status
+	}
+	if req.DurationMinutes != nil {
+		if *req.DurationMinutes < 0 {
+			return errors.New("duration_minutes must not be negative")
+		}
+		item.DurationMinutes = *req.DurationMinutes
+	}
+	if req.StartedAt != nil || req.EndedAt != nil {
+		startedAt, endedAt := formatItemTime(item.StartedAt), formatItemTime(item.EndedAt)
+		if req.StartedAt != nil {
+			startedAt = *req.StartedAt
+		}
+		if req.EndedAt != nil {
+			endedAt = *req.EndedAt
+		}
+		item.StartedAt, item.EndedAt, err = parseItemTimes(startedAt, endedAt)
+		if err != nil {
+			return err
+		}
+		if req.DurationMinutes == nil && item.StartedAt != nil {
+			item.DurationMinutes = trackedMinutes(item.StartedAt, item.EndedAt)
+		}
+	}
+
+	projectID := item.ProjectID
+	if req.ProjectID != nil {
+		projectID = *req.ProjectID
+	}
+	return assignItemProject(userID, item, projectID)
+}
+
+// DeleteWorkLogItem removes a bullet of a day; removing the last one deletes the work log
+func DeleteWorkLogItem(userID int64, date string, itemID int64) error {
+	date, err := ResolveDate(userID, date)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = editItems(userID, date, func(items []model.WorkLogItem) ([]model.WorkLogItem, bool, error) {
+		index := findItem(items, itemID)
+		if index < 0 {
+			return nil, false, nil // Treat as success if not found
+		}
+		return append(items[:index], items[index+1:]...), true, nil
+	})
+	return err
+}
+
+// ReorderWorkLogItems puts the bullets of a day in the given order and returns them
+// along with the resulting content
+func ReorderWorkLogItems(userID int64, date string, itemIDs []int64) ([]model.WorkLogItem, string, error) {
+	date, err := ResolveDate(userID, date)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return editItems(userID, date, func(items []model.WorkLogItem) ([]model.WorkLogItem, bool, error) {
+		if len(itemIDs) != len(items) {
+			return nil, false, errors.New("item_ids must list every item of the day exactly once")
+		}
+
+		reordered := make([]model.WorkLogItem, 0, len(items))
+		seen := make(map[int64]bool, len(itemIDs))
+		for _, id := range itemIDs {
+			index := findItem(items, id)
+			if index < 0 || seen[id] {
+				return nil, false, errors.New("item_ids must list every item of the day exactly once")
+			}
+			seen[id] = true
+			reordered = append(reordered, items[index])
+		}
+		return reordered, true, nil
+	})
+}
+
+// itemEdit turns the bullets of a day into the ones to save; false leaves the day untouched
+type itemEdit func(items []model.WorkLogItem) ([]model.WorkLogItem, bool, error)
+
+// editItems runs an edit on the bullets of a day and saves the result, returning the saved
+// items and content. The day stays locked from the read to the write so concurrent edits
+// are not lost, and the content, items and revision are written in one transaction.
+// Only the lines of changed bullets are rewritten; saving no bullets deletes the work log.
+func editItems(userID int64, date string, edit itemEdit) ([]model.WorkLogItem, string, error) {
+	if !dateRegex.MatchString(date) {
+		return nil, "", errors.New("date must be in YYYY-MM-DD format")
+	}
+
+	tx, err := datastore.DB.Beginx()
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	current, err := work_log_repo.LockByDate(tx, userID, date)
+	if err != nil {
+		return nil, "", err
+	}
+	if current != nil && current.DeletedAt != nil {
+		return nil, "", ErrWorkLogTrashed
+	}
+
+	previous := []model.WorkLogItem{}
+	if current != nil {
+		if previous, err = work_log_item_repo.ListByWorkLogIDTx(tx, current.ID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	items, ok, err := edit(append([]model.WorkLogItem(nil), previous...))
+	if err != nil || !ok {
+		return nil, "", err
+	}
+
+	if len(items) == 0 {
+		if current == nil {
+			return items, "", nil
+		}
+		if err := work_log_revision_repo.CreateTx(tx, newRevision(current, RevisionReasonDelete)); err != nil {
+			return nil, "", err
+		}
+		if err := work_log_repo.DeleteByDateTx(tx, userID, date); err != nil {
+			return nil, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, "", err
+		}
+		workLogDeleted(userID, date)
+		return items, "", nil
+	}
+
+	content := work_log_item_service.RenderContent(items)
+	if current != nil {
+		content = work_log_item_service.MergeContent(current.Content, previous, items)
+		if current.Content != content {
+			if err := work_log_revision_repo.CreateTx(tx, newRevision(current, RevisionReasonUpdate)); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	workLog, err := work_log_repo.UpsertTx(tx, userID, date, content)
+	if err != nil {
+		return nil, "", err
+	}
+	if workLog == nil {
+		return nil, "", ErrWorkLogTrashed
+	}
+	if err := work_log_item_repo.ReplaceForWorkLogTx(tx, workLog.ID, userID, items); err != nil {
+		return nil, "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, "", err
+	}
+	workLogSaved(workLog)
+
+	return items, content, nil
+}
+
+// assignItemProject links an item to a project (zero unlinks) and keeps its #tag in the text
+func assignItemProject(userID int64, item *model.WorkLogItem, projectID int64) error {
+	var previous, next *model.Project
+	var err error
+	if item.ProjectID != 0 {
+		if previous, err = project_repo.GetByID(item.ProjectID, userID); err != nil {
+			return err
+		}
+	}
+	if projectID != 0 {
+		if next, err = project_repo.GetByID(projectID, userID); err != nil {
+			return err
+		}
+		if next == nil {
+			return errors.New("project not found")
+		}
+	}
+
+	item.Text = work_log_item_service.SwapProjectTag(item.Text, previous, next)
+	item.ProjectID = projectID
+	return nil
+}
+
+// parseItemTimes reads the optional RFC3339 start and end of an item, which go together
+func parseItemTimes(startedAt, endedAt string) (*time.Time, *time.Time, error) {
+	if startedAt == "" && endedAt == "" {
+		return nil, nil, nil
+	}
+	if startedAt == "" || endedAt == "" {
+		return nil, nil, errors.New("started_at and ended_at must be given together")
+	}
+	start, err := time.Parse(time.RFC3339, startedAt)
+	if err != nil {
+		return nil, nil, errors.New("started_at must be an RFC3339 timestamp")
+	}
+	end, err := time.Parse(time.RFC3339, endedAt)
+	if err != nil {
+		return nil, nil, errors.New("ended_at must be an RFC3339 timestamp")
+	}
+	if !end.After(start) {
+		return nil, nil, errors.New("ended_at must be after started_at")
+	}
+	return &start, &end, nil
+}
+
+func formatItemTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// trackedMinutes rounds a time range up to whole minutes
+func trackedMinutes(startedAt, endedAt *time.Time) int {
+	if startedAt == nil || endedAt == nil {
+		return 0
+	}
+	return int(math.Ceil(endedAt.Sub(*startedAt).Minutes()))
+}
+
+func findItem(items []model.WorkLogItem, itemID int64) int {
+	for i, item := range items {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// newRevision builds the revision holding the current content of a day
+func newRevision(current *model.WorkLog, reason string) *model.WorkLogRevision {
+	return &model.WorkLogRevision{
+		UserID:  current.UserID,
+		Date:    current.Date,
+		Content: current.Content,
+		Reason:  reason,
+	}
+}
+
+// InvalidateCache drops the cached entry for a day along with every cached list of the user
+func InvalidateCache(userID int64, date string) {
+	if !cacheEnabled() {
+		return
+	}
+	if err := cache_repo.Delete(workLogDateKey(userID, date)); err != nil {
+		log.Warnf("work log cache invalidation failed: %v", err)
+	}
+	if err := cache_repo.DeleteTracked(workLogListIndexKey(userID)); err != nil {
+		log.Warnf("work log cache invalidation failed: %v", err)
+	}
+}
+
+func cacheEnabled() bool {
+	return config.Get().CacheEnabled
+}
+
+func workLogDateKey(userID int64, date string) string {
+	return fmt.Sprintf("work_logs:user:%d:date:%s", userID, date)
+}
+
+func workLogListKey(userID int64, filter work_log_repo.ListFilter) string {
+	return fmt.Sprintf("work_logs:user:%d:list:%s:%s:%s:%d:%t:%s:%s",
+		userID, filter.From, filter.To, filter.After, filter.Limit, filter.Asc, filter.TagKind, filter.TagName)
+}
+
+// workLogListIndexKey tracks every cached list of a user for invalidation
+func workLogListIndexKey(userID int64) string {
+	return fmt.Sprintf("work_logs:user:%d:lists", userID)
 }
@@ -0,0 +1,188 @@
+package work_log_stats_service
+
+import (
+	"errors"
+	"time"
+
+	"worknote-api/model"
+	"worknote-api/repos/work_log_repo"
+	"worknote-api/repos/work_log_tag_repo"
+	"worknote-api/services/calendar_service"
+	"worknote-api/services/user_settings_service"
+	"worknote-api/services/work_log_tag_service"
+)
+
+const (
+	topTagLimit = 10
+	heatLevels  = 4
+	// streakWindowDays is how far back each read of logged dates goes while
+	// walking the current streak
+	streakWindowDays = 366
+)
+
+// DayStat is one cell of the activity heatmap
+type DayStat struct {
+	model.WorkLogDayStat
+	Level int // 1..heatLevels, relative to the busiest day of the year
+}
+
+// Stats summarizes the logging activity of a user over a year
+type Stats struct {
+	Year          int
+	Days          []DayStat
+	Months        []model.WorkLogMonthStat
+	TopTags       []model.WorkLogTagCount
+	CurrentStreak int
+	LongestStreak int
+}
+
+// GetStats computes the activity heatmap, streaks, monthly counts and top tags of a year.
+// Streaks count consecutive workdays with a log; days off neither extend nor break them
+// unless something was logged on them.
+func GetStats(userID int64, year int) (*Stats, error) {
+	today, err := user_settings_service.Today(userID)
+	if err != nil {
+		return nil, err
+	}
+	if year == 0 {
+		year = today.Year()
+	}
+	if year < 1970 || year > today.Year()+1 {
+		return nil, errors.New("invalid year")
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	from, to := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	days, err := work_log_repo.DailyStats(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	months, err := work_log_repo.MonthlyStats(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	topTags, err := work_log_tag_repo.CountByUserID(userID, work_log_tag_service.KindTag, from, to, topTagLimit)
+	if err != nil {
+		return nil, err
+	}
+	dates, err := work_log_repo.ListDatesInRange(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	isWorkday, err := workdayChecker(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{
+		Year:    year,
+		Days:    toDayStats(days),
+		Months:  months,
+		TopTags: topTags,
+	}
+	if stats.Months == nil {
+		stats.Months = []model.WorkLogMonthStat{}
+	}
+	if stats.TopTags == nil {
+		stats.TopTags = []model.WorkLogTagCount{}
+	}
+	if end.After(today) {
+		end = today
+	}
+	stats.LongestStreak = longestStreak(toDateSet(dates), start, end, isWorkday)
+	stats.CurrentStreak, err = currentStreak(userID, today, isWorkday)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// workdayChecker tells which days count towards a streak. Days off in the user's calendar
+// neither extend nor break one.
+func workdayChecker(userID int64) (func(time.Time) bool, error) {
+	cal, err := calendar_service.LoadCalendar(userID)
+	if err != nil {
+		return nil, err
+	}
+	return cal.IsWorkday, nil
+}
+
+func toDayStats(days []model.WorkLogDayStat) []DayStat {
+	maxChars := 0
+	for _, day := range days {
+		if day.Chars > maxChars {
+			maxChars = day.Chars
+		}
+	}
+
+	stats := make([]DayStat, len(days))
+	for i, day := range days {
+		level := 1
+		if maxChars > 0 {
+			level = (day.Chars*heatLevels + maxChars - 1) / maxChars
+		}
+		if level < 1 {
+			level = 1
+		}
+		stats[i] = DayStat{WorkLogDayStat: day, Level: level}
+	}
+	return stats
+}
+
+// longestStreak walks a period and returns the longest run of logged days
+func longestStreak(logged map[string]bool, start, end time.Time, isWorkday func(time.Time) bool) int {
+	longest, run := 0, 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		switch {
+		case logged[day.Format("2006-01-02")]:
+			run++
+			if run > longest {
+				longest = run
+			}
+		case isWorkday(day):
+			run = 0
+		}
+	}
+	return longest
+}
+
+// currentStreak counts the logged days running up to today. An empty today
+// does not break the streak since the day is not over yet. Logged dates are read
+// one window at a time, only as far back as the streak goes.
+func currentStreak(userID int64, today time.Time, isWorkday func(time.Time) bool) (int, error) {
+	streak := 0
+	for windowEnd := today; ; {
+		windowStart := windowEnd.AddDate(0, 0, -(streakWindowDays - 1))
+		dates, err := work_log_repo.ListDatesInRange(userID, windowStart.Format("2006-01-02"), windowEnd.Format("2006-01-02"))
+		if err != nil {
+			return 0, err
+		}
+		if len(dates) == 0 {
+			return streak, nil
+		}
+
+		logged := toDateSet(dates)
+		for day := windowEnd; !day.Before(windowStart); day = day.AddDate(0, 0, -1) {
+			switch {
+			case logged[day.Format("2006-01-02")]:
+				streak++
+			case day.Equal(today):
+			case isWorkday(day):
+				return streak, nil
+			}
+		}
+		windowEnd = windowStart.AddDate(0, 0, -1)
+	}
+}
+
+func toDateSet(dates []string) map[string]bool {
+	set := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		set[date] = true
+	}
+	return set
+}
@@ -1,272 +1,935 @@
 package work_log_summary_service
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
 
 	"worknote-api/config"
+	"worknote-api/contract"
 	"worknote-api/model"
+	"worknote-api/repos/cache_repo"
+	"worknote-api/repos/user_repo"
 	"worknote-api/repos/work_log_repo"
 	"worknote-api/repos/work_log_summary_repo"
+	"worknote-api/services/background_job_service"
+	"worknote-api/services/calendar_service"
+	"worknote-api/services/event_service"
+	"worknote-api/services/llm_service"
+	"worknote-api/services/project_service"
+	"worknote-api/services/summary_template_service"
+	"worknote-api/services/user_settings_service"
+	"worknote-api/utils/period"
+)
+
+// JobTypeGenerateSummary is the background job type for summary generation
+const JobTypeGenerateSummary = "work_log_summary.generate"
+
+// Strategies of a generated summary
+const (
+	StrategySingle    = "single"     // One prompt with every log of the period
+	StrategyMapReduce = "map_reduce" // Weekly or finer summaries summarized again
+)
+
+// summaryAnswerTokens is kept free in the model context for the generated summary
+const summaryAnswerTokens = 4096
+
+// minChunkBytes is the least room a map-reduce prompt must leave for the logs of a day
+const minChunkBytes = 1024
+
+// Sources of a summary version
+const (
+	VersionGenerated = "generated"
+	VersionManual    = "manual"
 )
 
-// OpenRouter API types
-type openRouterRequest struct {
-	Model    string          `json:"model"`
-	Messages []openRouterMsg `json:"messages"`
+var (
+	// ErrSummaryNotFound is returned when the period has no summary yet
+	ErrSummaryNotFound = errors.New("summary not found for the specified period")
+	// ErrVersionNotFound is returned when pinning a version the summary does not have
+	ErrVersionNotFound = errors.New("summary version not found")
+)
+
+// generateSummaryJobPayload is the payload of a summary generation job
+type generateSummaryJobPayload struct {
+	PeriodType  string `json:"period_type,omitempty"`
+	PeriodStart string `json:"period_start,omitempty"`
+	PeriodEnd   string `json:"period_end,omitempty"`
+	ProjectID   int64  `json:"project_id,omitempty"`
+	Template    string `json:"template,omitempty"`
+	TemplateID  int64  `json:"template_id,omitempty"`
+}
+
+// Options selects the prompt template of a summary, the default style when empty
+type Options struct {
+	Template   string // Built-in style key
+	TemplateID int64  // User-defined template, takes precedence over Template
+}
+
+// GenerateSummary generates an AI-powered summary for a user's monthly work logs.
+// A non-zero projectID restricts the summary to the bullets of that project.
+// A summary needing the map-reduce strategy is queued instead and its job returned.
+func GenerateSummary(userID int64, month string, projectID int64, opts Options) (*model.WorkLogSummary, *model.BackgroundJob, error) {
+	p, err := parseMonth(month)
+	if err != nil {
+		return nil, nil, err
+	}
+	return GeneratePeriodSummary(userID, p, projectID, opts)
 }
 
-type openRouterMsg struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+// GeneratePeriodSummary generates an AI-powered summary of the work logs of a period.
+// A non-zero projectID restricts the summary to the bullets of that project.
+// A summary needing the map-reduce strategy takes several provider calls, so it is
+// queued as a background job instead and the job is returned.
+func GeneratePeriodSummary(userID int64, p period.Period, projectID int64, opts Options) (*model.WorkLogSummary, *model.BackgroundJob, error) {
+	plan, err := planSummary(userID, p, projectID, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if plan.strategy == StrategyMapReduce {
+		job, err := EnqueueGeneratePeriodSummary(userID, p, projectID, opts)
+		return nil, job, err
+	}
+
+	summary, err := plan.run(context.Background())
+	return summary, nil, err
 }
 
-type openRouterResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
+// generatePeriodSummary generates a summary in the calling goroutine whatever its strategy
+func generatePeriodSummary(ctx context.Context, userID int64, p period.Period, projectID int64, opts Options) (*model.WorkLogSummary, error) {
+	plan, err := planSummary(userID, p, projectID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return plan.run(ctx)
 }
 
-// GenerateSummary generates an AI-powered summary for a user's monthly work logs
-func GenerateSummary(userID int64, month string) (*model.WorkLogSummary, error) {
-	// Validate month format (YYYY-MM)
-	if !isValidMonthFormat(month) {
-		return nil, errors.New("invalid month format, expected YYYY-MM")
+// run generates the summary of the plan and saves it, unless ctx ended meanwhile
+func (plan *summaryPlan) run(ctx context.Context) (*model.WorkLogSummary, error) {
+	// Call the user's preferred AI provider for summarization
+	resp, err := plan.generate(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate summary: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to generate summary: %w", err)
 	}
 
-	// Fetch all work logs for the user in the specified month
-	workLogs, err := getWorkLogsForMonth(userID, month)
+	return plan.save(resp)
+}
+
+// SummaryStream is a monthly summary generation whose tokens are relayed while the provider writes them
+type SummaryStream struct {
+	plan *summaryPlan
+}
+
+// NewSummaryStream validates the month and builds the prompt, so errors surface before streaming starts.
+// A summary needing the map-reduce strategy cannot be streamed; it is queued instead and its job returned.
+func NewSummaryStream(userID int64, month string, projectID int64, opts Options) (*SummaryStream, *model.BackgroundJob, error) {
+	p, err := parseMonth(month)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plan, err := planSummary(userID, p, projectID, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if plan.strategy == StrategyMapReduce {
+		job, err := EnqueueGeneratePeriodSummary(userID, p, projectID, opts)
+		return nil, job, err
+	}
+
+	return &SummaryStream{plan: plan}, nil, nil
+}
+
+// Run streams the summary to onToken and persists it only once the provider completed successfully.
+// Cancelling ctx or returning an error from onToken aborts the generation without saving anything.
+func (s *SummaryStream) Run(ctx context.Context, onToken llm_service.TokenFunc) (*model.WorkLogSummary, error) {
+	resp, err := s.plan.generate(ctx, onToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	return s.plan.save(resp)
+}
+
+// summaryPlan holds the prompts of a summary generation and how they are sent
+type summaryPlan struct {
+	userID    int64
+	period    period.Period
+	projectID int64
+	project   *model.Project
+	template  *summary_template_service.Template
+	provider  string        // The user's AI provider, empty for the server default
+	prompt    string        // Prompt of the whole period
+	strategy  string        // StrategySingle or StrategyMapReduce
+	chunks    []summaryPart // Prompts of the map-reduce strategy, one per week or less
+}
+
+// summaryPart is the prompt of one part of a map-reduce summary
+type summaryPart struct {
+	period period.Period
+	prompt string
+}
+
+// summaryNote is what the provider wrote about one part of a map-reduce summary
+type summaryNote struct {
+	period period.Period
+	text   string
+}
+
+// planSummary gathers the work logs and days off of a period into the summary prompt. When that
+// prompt would not fit the model's context, the logs are split by week, or finer, to be summarized separately.
+func planSummary(userID int64, p period.Period, projectID int64, opts Options) (*summaryPlan, error) {
+	project, err := project_service.ResolveProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := summary_template_service.Resolve(userID, opts.Template, opts.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch all work logs for the user in the period
+	workLogs, err := work_log_repo.ListByUserIDAndDateRange(userID, p.StartDate(), p.EndDate())
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch work logs: %w", err)
 	}
+	workLogs = project_service.FilterWorkLogs(workLogs, project)
 
 	if len(workLogs) == 0 {
-		return nil, errors.New("no work logs found for the specified month")
+		return nil, errors.New("no work logs found for the specified period")
 	}
 
-	// Build content string from work logs
-	content := buildWorkLogContent(workLogs)
+	daysOff, err := calendar_service.ListDaysOff(userID, p.StartDate(), p.EndDate())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch days off: %w", err)
+	}
 
-	// Call OpenRouter API for summarization
-	summary, err := callOpenRouter(content)
+	settings, err := user_settings_service.GetSettings(userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate summary: %w", err)
+		return nil, err
+	}
+
+	// Build content string from work logs
+	plan := &summaryPlan{
+		userID:    userID,
+		period:    p,
+		projectID: projectID,
+		provider:  settings.AIProvider,
+		template:  template,
+		project:   project,
+		strategy:  StrategySingle,
+	}
+	plan.prompt = plan.buildPrompt(buildWorkLogContent(workLogs, project, daysOff, p))
+	if fitsPromptBudget(plan.prompt) {
+		return plan, nil
+	}
+
+	plan.strategy = StrategyMapReduce
+	for _, week := range splitByWeek(p) {
+		if err := plan.addChunks(workLogs, daysOff, week); err != nil {
+			return nil, err
+		}
 	}
+	log.Infof("summary of %s for user %d exceeds the model context, summarizing %d parts separately", p.Label(), userID, len(plan.chunks))
 
-	// Upsert the summary to database
-	return work_log_summary_repo.Upsert(userID, month, summary)
+	return plan, nil
 }
 
-// GetSummary retrieves an existing summary for a user's month
-func GetSummary(userID int64, month string) (*model.WorkLogSummary, error) {
-	if !isValidMonthFormat(month) {
-		return nil, errors.New("invalid month format, expected YYYY-MM")
+// addChunks appends the prompts covering a period of a map-reduce summary. A period whose
+// prompt does not fit the budget is split into weeks, then days, and a day into groups of lines.
+func (plan *summaryPlan) addChunks(workLogs []model.WorkLog, daysOff []model.CalendarDay, p period.Period) error {
+	workLogs = filterWorkLogsInPeriod(workLogs, p)
+	if len(workLogs) == 0 {
+		return nil
+	}
+	daysOff = filterDaysOffInPeriod(daysOff, p)
+
+	prompt := buildChunkPrompt(buildWorkLogContent(workLogs, plan.project, daysOff, p))
+	if fitsPromptBudget(prompt) {
+		plan.chunks = append(plan.chunks, summaryPart{period: p, prompt: prompt})
+		return nil
+	}
+
+	if p.Start.Before(p.End) {
+		parts := splitByWeek(p)
+		if len(parts) == 1 {
+			parts = splitByDay(p)
+		}
+		for _, part := range parts {
+			if err := plan.addChunks(workLogs, daysOff, part); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	return work_log_summary_repo.GetByMonth(userID, month)
+
+	// A day is the log of one date, cut into groups of lines that leave room for the rest of the prompt
+	workLog := workLogs[0]
+	workLog.Content = ""
+	overhead := llm_service.EstimateTokens(buildChunkPrompt(buildWorkLogContent([]model.WorkLog{workLog}, plan.project, daysOff, p)))
+	maxBytes := (promptTokenBudget() - overhead) * 4
+	if maxBytes < minChunkBytes {
+		return errors.New("the model context is too small to summarize this period")
+	}
+	for _, content := range splitContent(workLogs[0].Content, maxBytes) {
+		workLog.Content = content
+		plan.chunks = append(plan.chunks, summaryPart{
+			period: p,
+			prompt: buildChunkPrompt(buildWorkLogContent([]model.WorkLog{workLog}, plan.project, daysOff, p)),
+		})
+	}
+	return nil
 }
 
-// isValidMonthFormat validates the month format (YYYY-MM)
-func isValidMonthFormat(month string) bool {
-	match, _ := regexp.MatchString(`^\d{4}-(0[1-9]|1[0-2])$`, month)
-	return match
+// generate sends the plan to the provider, streaming the final answer to onToken when given.
+// Token counts add up every call of a map-reduce summary.
+func (plan *summaryPlan) generate(ctx context.Context, onToken llm_service.TokenFunc) (*llm_service.Response, error) {
+	if plan.strategy == StrategySingle {
+		return plan.send(ctx, plan.prompt, onToken)
+	}
+
+	notes := make([]summaryNote, len(plan.chunks))
+	promptTokens, completionTokens := 0, 0
+	for i, chunk := range plan.chunks {
+		resp, err := plan.send(ctx, chunk.prompt, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize %s: %w", chunk.period.Describe(), err)
+		}
+		notes[i] = summaryNote{period: chunk.period, text: resp.Content}
+		promptTokens += resp.PromptTokens
+		completionTokens += resp.CompletionTokens
+	}
+
+	// Notes too long for one prompt are merged in groups, level after level, until they fit
+	for {
+		prompt := plan.buildReducePrompt(notes)
+		if fitsPromptBudget(prompt) {
+			resp, err := plan.send(ctx, prompt, onToken)
+			if err != nil {
+				return nil, err
+			}
+			resp.PromptTokens += promptTokens
+			resp.CompletionTokens += completionTokens
+			return resp, nil
+		}
+
+		groups := groupNotes(notes)
+		if len(groups) == len(notes) {
+			return nil, errors.New("the notes of this period do not fit the model context")
+		}
+		merged := make([]summaryNote, len(groups))
+		for i, group := range groups {
+			resp, err := plan.send(ctx, buildMergePrompt(group), nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge notes: %w", err)
+			}
+			merged[i] = summaryNote{
+				period: period.Period{Type: period.Custom, Start: group[0].period.Start, End: group[len(group)-1].period.End},
+				text:   resp.Content,
+			}
+			promptTokens += resp.PromptTokens
+			completionTokens += resp.CompletionTokens
+		}
+		notes = merged
+	}
 }
 
-// getWorkLogsForMonth retrieves work logs for a specific month
-func getWorkLogsForMonth(userID int64, month string) ([]model.WorkLog, error) {
-	allLogs, err := work_log_repo.ListByUserID(userID)
-	if err != nil {
+// groupNotes packs consecutive notes into groups whose merge prompt fits the budget.
+// A note too long to share a prompt makes a group of its own.
+func groupNotes(notes []summaryNote) [][]summaryNote {
+	var groups [][]summaryNote
+	var current []summaryNote
+	for _, note := range notes {
+		if len(current) > 0 && !fitsPromptBudget(buildMergePrompt(append(current, note))) {
+			groups = append(groups, current)
+			current = nil
+		}
+		current = append(current, note)
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+func (plan *summaryPlan) send(ctx context.Context, prompt string, onToken llm_service.TokenFunc) (*llm_service.Response, error) {
+	if onToken != nil {
+		return llm_service.StreamPrompt(ctx, plan.provider, prompt, onToken)
+	}
+	return llm_service.CompletePrompt(ctx, plan.provider, prompt)
+}
+
+// save stores a generated summary as a new version and notifies the user's clients.
+// The prompt hash is the one of the whole period so that both strategies compare.
+func (plan *summaryPlan) save(resp *llm_service.Response) (*model.WorkLogSummary, error) {
+	promptHash := sha256.Sum256([]byte(plan.prompt))
+	workLogSummary := &model.WorkLogSummary{
+		UserID:      plan.userID,
+		ProjectID:   plan.projectID,
+		PeriodType:  plan.period.Type,
+		PeriodStart: plan.period.StartDate(),
+		PeriodEnd:   plan.period.EndDate(),
+		Summary:     resp.Content,
+	}
+	version := &model.WorkLogSummaryVersion{
+		Summary:          resp.Content,
+		Source:           VersionGenerated,
+		Strategy:         plan.strategy,
+		Template:         plan.template.Key,
+		TemplateID:       plan.template.ID,
+		TemplateVersion:  plan.template.Version,
+		Provider:         resp.Provider,
+		Model:            resp.Model,
+		PromptHash:       hex.EncodeToString(promptHash[:]),
+		PromptTokens:     resp.PromptTokens,
+		CompletionTokens: resp.CompletionTokens,
+	}
+	if err := work_log_summary_repo.Upsert(workLogSummary, version); err != nil {
 		return nil, err
 	}
+	invalidateSummaryCache(plan.userID, plan.period, plan.projectID)
+
+	event_service.Publish(plan.userID, event_service.EventSummaryGenerated, contract.SummaryGeneratedEventData{
+		ID:          workLogSummary.ID,
+		ProjectID:   workLogSummary.ProjectID,
+		Month:       MonthOf(workLogSummary),
+		PeriodType:  workLogSummary.PeriodType,
+		PeriodStart: workLogSummary.PeriodStart,
+		PeriodEnd:   workLogSummary.PeriodEnd,
+	})
+
+	return workLogSummary, nil
+}
+
+// promptTokenBudget is the part of the model context left for the prompt once the answer is reserved
+func promptTokenBudget() int {
+	return config.Get().LLMContextTokens - summaryAnswerTokens
+}
 
-	var monthLogs []model.WorkLog
-	for _, log := range allLogs {
-		// log.Date is in YYYY-MM-DD format; check if it starts with the month
-		if strings.HasPrefix(log.Date, month) {
-			monthLogs = append(monthLogs, log)
+func fitsPromptBudget(prompt string) bool {
+	return llm_service.EstimateTokens(prompt) <= promptTokenBudget()
+}
+
+// splitByWeek cuts a period into ISO weeks, the first and last ones clipped to the period
+func splitByWeek(p period.Period) []period.Period {
+	var weeks []period.Period
+	for start := p.Start; !start.After(p.End); {
+		week, _ := period.Containing(start, period.Week)
+		end := week.End
+		if end.After(p.End) {
+			end = p.End
 		}
+		weeks = append(weeks, period.Period{Type: period.Custom, Start: start, End: end})
+		start = end.AddDate(0, 0, 1)
 	}
+	return weeks
+}
 
-	return monthLogs, nil
+// splitByDay cuts a period into single days
+func splitByDay(p period.Period) []period.Period {
+	var days []period.Period
+	for day := p.Start; !day.After(p.End); day = day.AddDate(0, 0, 1) {
+		days = append(days, period.Period{Type: period.Custom, Start: day, End: day})
+	}
+	return days
 }
 
-// buildWorkLogContent formats work logs into a string for the AI prompt
-func buildWorkLogContent(logs []model.WorkLog) string {
-	var sb strings.Builder
-	sb.WriteString("Here are my daily work logs for this month:\n\n")
-	for _, log := range logs {
-		sb.WriteString(fmt.Sprintf("## %s\n%s\n\n", log.Date, log.Content))
+// splitContent cuts a text into pieces of at most maxBytes, at line breaks where it can
+func splitContent(content string, maxBytes int) []string {
+	var pieces []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			pieces = append(pieces, current.String())
+			current.Reset()
+		}
 	}
-	return sb.String()
+	for _, line := range strings.Split(content, "\n") {
+		for len(line) > maxBytes {
+			cut := maxBytes
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			flush()
+			pieces = append(pieces, line[:cut])
+			line = line[cut:]
+		}
+		if current.Len() > 0 && current.Len()+1+len(line) > maxBytes {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(line)
+	}
+	flush()
+	return pieces
 }
 
-// callOpenRouter calls the OpenRouter API to generate a summary
-func callOpenRouter(content string) (string, error) {
-	cfg := config.Get()
+// filterWorkLogsInPeriod keeps the work logs dated within a period
+func filterWorkLogsInPeriod(logs []model.WorkLog, p period.Period) []model.WorkLog {
+	var filtered []model.WorkLog
+	for _, workLog := range logs {
+		date := workLog.Date
+		if len(date) > 10 {
+			date = date[:10]
+		}
+		if date >= p.StartDate() && date <= p.EndDate() {
+			filtered = append(filtered, workLog)
+		}
+	}
+	return filtered
+}
 
-	if cfg.OpenRouterAPIKey == "" {
-		return "", errors.New("OPENROUTER_API_KEY is not configured")
+// filterDaysOffInPeriod keeps the days off within a period
+func filterDaysOffInPeriod(days []model.CalendarDay, p period.Period) []model.CalendarDay {
+	var filtered []model.CalendarDay
+	for _, day := range days {
+		if day.Date >= p.StartDate() && day.Date <= p.EndDate() {
+			filtered = append(filtered, day)
+		}
 	}
+	return filtered
+}
 
-	prompt := fmt.Sprintf(`You are a helpful assistant that summarizes work logs.
+// EnqueueGenerateSummary validates the month and queues summary generation as a background job
+func EnqueueGenerateSummary(userID int64, month string, projectID int64, opts Options) (*model.BackgroundJob, error) {
+	p, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	return EnqueueGeneratePeriodSummary(userID, p, projectID, opts)
+}
 
-Please provide a concise but comprehensive summary of the following monthly work activities.
-Highlight key accomplishments, recurring themes, and notable patterns.
-Format the summary in a clear, professional manner.
+// EnqueueGeneratePeriodSummary queues summary generation of a period as a background job
+func EnqueueGeneratePeriodSummary(userID int64, p period.Period, projectID int64, opts Options) (*model.BackgroundJob, error) {
+	if _, err := project_service.ResolveProject(userID, projectID); err != nil {
+		return nil, err
+	}
+	if _, err := summary_template_service.Resolve(userID, opts.Template, opts.TemplateID); err != nil {
+		return nil, err
+	}
+	return background_job_service.Enqueue(userID, JobTypeGenerateSummary, generateSummaryJobPayload{
+		PeriodType:  p.Type,
+		PeriodStart: p.StartDate(),
+		PeriodEnd:   p.EndDate(),
+		ProjectID:   projectID,
+		Template:    opts.Template,
+		TemplateID:  opts.TemplateID,
+	})
+}
 
-%s`, content)
+// HandleGenerateSummaryJob runs a queued summary generation job
+func HandleGenerateSummaryJob(ctx context.Context, job *model.BackgroundJob) (interface{}, error) {
+	var payload generateSummaryJobPayload
+	if err := background_job_service.DecodePayload(job, &payload); err != nil {
+		return nil, err
+	}
 
-	reqBody := openRouterRequest{
-		Model: cfg.OpenRouterModel,
-		Messages: []openRouterMsg{
-			{Role: "user", Content: prompt},
-		},
+	p, err := period.FromDates(payload.PeriodType, payload.PeriodStart, payload.PeriodEnd)
+	if err != nil {
+		return nil, err
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
+	workLogSummary, err := generatePeriodSummary(ctx, job.UserID, p, payload.ProjectID, Options{
+		Template:   payload.Template,
+		TemplateID: payload.TemplateID,
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", "https://openrouter.ai/api/v1/chat/completions", bytes.NewBuffer(jsonBody))
+	return map[string]interface{}{
+		"summary_id":   workLogSummary.ID,
+		"period_type":  workLogSummary.PeriodType,
+		"period_start": workLogSummary.PeriodStart,
+		"period_end":   workLogSummary.PeriodEnd,
+		"project_id":   workLogSummary.ProjectID,
+	}, nil
+}
+
+// SetAutoSummary opts a user in or out of scheduled monthly summaries
+func SetAutoSummary(userID int64, enabled bool) error {
+	return user_repo.UpdateAutoSummaryEnabled(userID, enabled)
+}
+
+// GetAutoSummary reports whether a user is opted into scheduled monthly summaries
+func GetAutoSummary(userID int64) (bool, error) {
+	user, err := user_repo.GetByID(userID)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return false, err
 	}
+	if user == nil {
+		return false, errors.New("user not found")
+	}
+	return user.AutoSummaryEnabled, nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+cfg.OpenRouterAPIKey)
+// RunAutoSummaries is the scheduled task that queues the previous month's summary for
+// every opted-in user with logs in that month. Queued jobs retry on LLM failure.
+func RunAutoSummaries(ctx context.Context, scheduledAt time.Time) error {
+	firstOfMonth := time.Date(scheduledAt.Year(), scheduledAt.Month(), 1, 0, 0, 0, 0, scheduledAt.Location())
+	previous := firstOfMonth.AddDate(0, -1, 0)
+	month := previous.Format("2006-01")
+	startDate := previous.Format("2006-01-02")
+	endDate := firstOfMonth.AddDate(0, 0, -1).Format("2006-01-02")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	userIDs, err := user_repo.ListAutoSummaryUserIDsInRange(startDate, endDate)
 	if err != nil {
-		return "", fmt.Errorf("failed to call OpenRouter API: %w", err)
+		return fmt.Errorf("failed to list users for auto summary: %w", err)
+	}
+
+	queued := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Do not overwrite a summary the user already generated by hand
+		existing, err := work_log_summary_repo.GetByPeriod(userID, period.Month, startDate, endDate, 0)
+		if err != nil {
+			log.Errorf("auto summary: failed to check existing summary for user %d: %v", userID, err)
+			continue
+		}
+		if existing != nil {
+			continue
+		}
+
+		if _, err := EnqueueGenerateSummary(userID, month, 0, Options{}); err != nil {
+			log.Errorf("auto summary: failed to queue summary for user %d: %v", userID, err)
+			continue
+		}
+		queued++
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	log.Infof("auto summary: queued %d summaries for %s", queued, month)
+	return nil
+}
+
+// GetSummary retrieves an existing summary for a user's month, optionally scoped to a project
+func GetSummary(userID int64, month string, projectID int64, bypassCache bool) (*model.WorkLogSummary, error) {
+	p, err := parseMonth(month)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
+	return GetPeriodSummary(userID, p, projectID, bypassCache)
+}
 
-	var openRouterResp openRouterResponse
-	if err := json.Unmarshal(body, &openRouterResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+// GetPeriodSummary retrieves an existing summary of a period, optionally scoped to a project
+func GetPeriodSummary(userID int64, p period.Period, projectID int64, bypassCache bool) (*model.WorkLogSummary, error) {
+	cfg := config.Get()
+	key := summaryKey(userID, p, projectID)
+	if !bypassCache && cfg.CacheEnabled {
+		var cached model.WorkLogSummary
+		hit, err := cache_repo.GetJSON(key, &cached)
+		if err != nil {
+			log.Warnf("summary cache read failed: %v", err)
+		} else if hit {
+			return &cached, nil
+		}
 	}
 
-	if openRouterResp.Error != nil {
-		return "", fmt.Errorf("OpenRouter error: %s", openRouterResp.Error.Message)
+	workLogSummary, err := work_log_summary_repo.GetByPeriod(userID, p.Type, p.StartDate(), p.EndDate(), projectID)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(openRouterResp.Choices) == 0 {
-		return "", errors.New("no response from OpenRouter")
+	if workLogSummary != nil && cfg.CacheEnabled {
+		if err := cache_repo.SetJSON(key, workLogSummary, cfg.CacheSummaryTTL); err != nil {
+			log.Warnf("summary cache write failed: %v", err)
+		}
 	}
 
-	return openRouterResp.Choices[0].Message.Content, nil
+	return workLogSummary, nil
 }
 
-// Z.AI API types
-type zaiRequest struct {
-	Model       string       `json:"model"`
-	Messages    []zaiMsg     `json:"messages"`
-	Thinking    *zaiThinking `json:"thinking,omitempty"`
-	MaxTokens   int          `json:"max_tokens,omitempty"`
-	Temperature float64      `json:"temperature,omitempty"`
-}
+// ListSummaryVersions retrieves the summary of a period along with every version of it, newest first
+func ListSummaryVersions(userID int64, p period.Period, projectID int64) (*model.WorkLogSummary, []model.WorkLogSummaryVersion, error) {
+	workLogSummary, err := work_log_summary_repo.GetByPeriod(userID, p.Type, p.StartDate(), p.EndDate(), projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if workLogSummary == nil {
+		return nil, nil, ErrSummaryNotFound
+	}
 
-type zaiMsg struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	versions, err := work_log_summary_repo.ListVersions(workLogSummary.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return workLogSummary, versions, nil
 }
 
-type zaiThinking struct {
-	Type string `json:"type"`
+// EditSummary stores a hand-edited text as a new version of the summary of a period and pins it,
+// so that later regenerations do not replace it
+func EditSummary(userID int64, p period.Period, projectID int64, text string) (*model.WorkLogSummary, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("summary is required")
+	}
+
+	workLogSummary, err := work_log_summary_repo.GetByPeriod(userID, p.Type, p.StartDate(), p.EndDate(), projectID)
+	if err != nil {
+		return nil, err
+	}
+	if workLogSummary == nil {
+		return nil, ErrSummaryNotFound
+	}
+
+	version := &model.WorkLogSummaryVersion{Summary: text, Source: VersionManual}
+	if err := work_log_summary_repo.UpsertPinned(workLogSummary, version); err != nil {
+		return nil, err
+	}
+	invalidateSummaryCache(userID, p, projectID)
+
+	return workLogSummary, nil
 }
 
-type zaiResponse struct {
-	Choices []struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-	} `json:"error,omitempty"`
+// PinSummaryVersion makes a version the current text of the summary of a period until unpinned
+func PinSummaryVersion(userID int64, p period.Period, projectID int64, version int) (*model.WorkLogSummary, error) {
+	workLogSummary, err := work_log_summary_repo.GetByPeriod(userID, p.Type, p.StartDate(), p.EndDate(), projectID)
+	if err != nil {
+		return nil, err
+	}
+	if workLogSummary == nil {
+		return nil, ErrSummaryNotFound
+	}
+
+	found, err := work_log_summary_repo.Pin(workLogSummary.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrVersionNotFound
+	}
+	invalidateSummaryCache(userID, p, projectID)
+
+	return work_log_summary_repo.GetByPeriod(userID, p.Type, p.StartDate(), p.EndDate(), projectID)
 }
 
-// callZai calls the Z.AI API to generate a summary
-// https://docs.z.ai/guides/llm/glm-4.7#quick-start
-func callZai(content string) (string, error) {
-	cfg := config.Get()
+// UnpinSummary lets the summary of a period follow its latest version again
+func UnpinSummary(userID int64, p period.Period, projectID int64) (*model.WorkLogSummary, error) {
+	workLogSummary, err := work_log_summary_repo.GetByPeriod(userID, p.Type, p.StartDate(), p.EndDate(), projectID)
+	if err != nil {
+		return nil, err
+	}
+	if workLogSummary == nil {
+		return nil, ErrSummaryNotFound
+	}
 
-	if cfg.ZaiAPIKey == "" {
-		return "", errors.New("ZAI_API_KEY is not configured")
+	if err := work_log_summary_repo.Unpin(workLogSummary.ID); err != nil {
+		return nil, err
 	}
+	invalidateSummaryCache(userID, p, projectID)
 
-	prompt := fmt.Sprintf(`You are a helpful assistant that summarizes work logs.
+	return work_log_summary_repo.GetByPeriod(userID, p.Type, p.StartDate(), p.EndDate(), projectID)
+}
 
-Please provide a concise but comprehensive summary of the following monthly work activities.
-Highlight key accomplishments, recurring themes, and notable patterns.
-Format the summary in a clear, professional manner.
+// ListSummaries retrieves a page of a user's summaries of one period type, newest first
+func ListSummaries(userID int64, periodType string, projectID int64, limit, offset int) ([]model.WorkLogSummary, int, error) {
+	if !period.IsValidType(periodType) {
+		return nil, 0, errors.New("period_type must be week, month, quarter, year or custom")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return work_log_summary_repo.ListByPeriodType(userID, periodType, projectID, limit, offset)
+}
 
-%s`, content)
+// ResolvePeriod builds the period of a summary request. Without a period label the current
+// one of the type in the user's timezone is used; custom periods take start and end dates.
+func ResolvePeriod(userID int64, periodType, label, start, end string) (period.Period, error) {
+	if periodType == "" {
+		if label == "" {
+			return period.Period{}, errors.New("period_type or period is required")
+		}
+		return period.Parse(label)
+	}
+	if !period.IsValidType(periodType) {
+		return period.Period{}, errors.New("period_type must be week, month, quarter, year or custom")
+	}
 
-	reqBody := zaiRequest{
-		Model: cfg.ZaiModel,
-		Messages: []zaiMsg{
-			{Role: "user", Content: prompt},
-		},
-		Thinking:    &zaiThinking{Type: "enabled"},
-		MaxTokens:   4096,
-		Temperature: 1.0,
+	if periodType == period.Custom {
+		if label != "" {
+			return period.ParseType(periodType, label)
+		}
+		if start == "" || end == "" {
+			return period.Period{}, errors.New("start and end are required for a custom period")
+		}
+		return period.NewCustom(start, end)
+	}
+	if label != "" {
+		return period.ParseType(periodType, label)
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
+	today, err := user_settings_service.Today(userID)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return period.Period{}, err
 	}
+	return period.Containing(today, periodType)
+}
 
-	req, err := http.NewRequest("POST", "https://api.z.ai/api/paas/v4/chat/completions", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+// PeriodOf rebuilds the period of a stored summary
+func PeriodOf(workLogSummary *model.WorkLogSummary) period.Period {
+	p, _ := period.FromDates(workLogSummary.PeriodType, workLogSummary.PeriodStart, workLogSummary.PeriodEnd)
+	return p
+}
+
+// MonthOf returns the YYYY-MM of a monthly summary, empty for other periods
+func MonthOf(workLogSummary *model.WorkLogSummary) string {
+	if workLogSummary.PeriodType != period.Month {
+		return ""
 	}
+	return PeriodOf(workLogSummary).Label()
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+cfg.ZaiAPIKey)
+// invalidateSummaryCache drops the cached summary of a period
+func invalidateSummaryCache(userID int64, p period.Period, projectID int64) {
+	if !config.Get().CacheEnabled {
+		return
+	}
+	if err := cache_repo.Delete(summaryKey(userID, p, projectID)); err != nil {
+		log.Warnf("summary cache invalidation failed: %v", err)
+	}
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func summaryKey(userID int64, p period.Period, projectID int64) string {
+	return fmt.Sprintf("summaries:user:%d:period:%s:project:%d", userID, p.Label(), projectID)
+}
+
+// CurrentMonth returns the current month (YYYY-MM) in the user's timezone
+func CurrentMonth(userID int64) (string, error) {
+	today, err := user_settings_service.Today(userID)
 	if err != nil {
-		return "", fmt.Errorf("failed to call Z.AI API: %w", err)
+		return "", err
 	}
-	defer resp.Body.Close()
+	return today.Format("2006-01"), nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// parseMonth validates the month format (YYYY-MM) and returns its period
+func parseMonth(month string) (period.Period, error) {
+	p, err := period.ParseType(period.Month, month)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return period.Period{}, errors.New("invalid month format, expected YYYY-MM")
 	}
+	return p, nil
+}
 
-	var zaiResp zaiResponse
-	if err := json.Unmarshal(body, &zaiResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+// buildWorkLogContent formats work logs into a string for the AI prompt. Days off are listed
+// so the summary does not read missing days as inactivity.
+func buildWorkLogContent(logs []model.WorkLog, project *model.Project, daysOff []model.CalendarDay, p period.Period) string {
+	var sb strings.Builder
+	if project != nil {
+		sb.WriteString(fmt.Sprintf("Here are my daily work logs for the project %q during %s:\n\n", project.Name, p.Describe()))
+	} else {
+		sb.WriteString(fmt.Sprintf("Here are my daily work logs during %s:\n\n", p.Describe()))
+	}
+	for _, log := range logs {
+		sb.WriteString(fmt.Sprintf("## %s\n%s\n\n", log.Date, log.Content))
+	}
+	if len(daysOff) > 0 {
+		sb.WriteString("I was not working on these days (leave and public holidays):\n")
+		for _, day := range daysOff {
+			line := fmt.Sprintf("- %s: %s", day.Date, day.Kind)
+			if day.Name != "" {
+				line += " (" + day.Name + ")"
+			}
+			sb.WriteString(line + "\n")
+		}
 	}
+	return sb.String()
+}
+
+// buildChunkPrompt asks for the notes of one part of a map-reduce summary
+func buildChunkPrompt(content string) string {
+	return fmt.Sprintf(`You are a helpful assistant that summarizes work logs.
+
+The following work logs are one part of a longer period that will be summarized from these notes.
+Write compact notes of the accomplishments, ongoing work, blockers and days off they cover.
+Keep concrete names, numbers and outcomes, and leave out any introduction.
+
+%s`, content)
+}
+
+// buildReducePrompt combines the notes of a map-reduce summary into the summary template
+func (plan *summaryPlan) buildReducePrompt(notes []summaryNote) string {
+	content := fmt.Sprintf("Here are notes summarizing each part of my work logs during %s:\n\n%s\n",
+		plan.period.Describe(), formatNotes(notes))
+	return plan.buildPrompt(content)
+}
 
-	if zaiResp.Error != nil {
-		return "", fmt.Errorf("Z.AI error: %s", zaiResp.Error.Message)
+// buildMergePrompt asks to condense consecutive notes of a map-reduce summary into one
+func buildMergePrompt(notes []summaryNote) string {
+	return fmt.Sprintf(`You are a helpful assistant that summarizes work logs.
+
+The following notes each summarize a part of a longer period that will be summarized from your answer.
+Merge them into one set of compact notes of the accomplishments, ongoing work, blockers and days off.
+Keep concrete names, numbers, outcomes and dates, and leave out any introduction.
+
+%s`, formatNotes(notes))
+}
+
+func formatNotes(notes []summaryNote) string {
+	parts := make([]string, len(notes))
+	for i, note := range notes {
+		parts[i] = fmt.Sprintf("## %s to %s\n%s", note.period.StartDate(), note.period.EndDate(), note.text)
 	}
+	return strings.Join(parts, "\n\n")
+}
 
-	if len(zaiResp.Choices) == 0 {
-		return "", errors.New("no response from Z.AI")
+// buildPrompt renders the summary template of the plan around formatted work logs
+func (plan *summaryPlan) buildPrompt(content string) string {
+	vars := summary_template_service.Variables{
+		Logs:            content,
+		Period:          plan.period.Describe(),
+		PeriodAdjective: periodAdjective(plan.period),
+	}
+	if plan.project != nil {
+		vars.Project = plan.project.Name
 	}
+	return summary_template_service.Render(plan.template, vars)
+}
 
-	return zaiResp.Choices[0].Message.Content, nil
+// periodAdjective qualifies the work activities of a period in the prompt
+func periodAdjective(p period.Period) string {
+	switch p.Type {
+	case period.Week:
+		return "weekly"
+	case period.Month:
+		return "monthly"
+	case period.Quarter:
+		return "quarterly"
+	case period.Year:
+		return "yearly"
+	}
+	return fmt.Sprintf("%d days of", int(p.End.Sub(p.Start).Hours()/24)+1)
+}
+
+// Complete sends a prompt to the LLM provider chosen in the user's settings, the server
+// default otherwise. Other services use it to reuse the summary LLM client.
+func Complete(userID int64, prompt string) (string, error) {
+	settings, err := user_settings_service.GetSettings(userID)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := llm_service.CompletePrompt(context.Background(), settings.AIProvider, prompt)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
 }
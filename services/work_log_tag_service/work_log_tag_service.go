@@ -0,0 +1,107 @@
+package work_log_tag_service
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/work_log_tag_repo"
+)
+
+// Tag kinds
+const (
+	KindTag     = "tag"
+	KindMention = "mention"
+)
+
+const (
+	defaultTagLimit = 100
+	maxTagLimit     = 500
+)
+
+var (
+	// Matches #project-x and @teammate when not glued to a preceding word,
+	// so emails and URL fragments are ignored. Keep in sync with migration 010.
+	tagRegex  = regexp.MustCompile(`(^|[^\p{L}\p{N}_])([#@])([A-Za-z][\p{L}\p{N}_-]*)`)
+	dateRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// ParseTags extracts the distinct #tags and @mentions of a content, lowercased
+func ParseTags(content string) []model.WorkLogTag {
+	counts := map[[2]string]int{}
+	for _, match := range tagRegex.FindAllStringSubmatch(content, -1) {
+		name := strings.TrimRight(strings.ToLower(match[3]), "-")
+		if name == "" {
+			continue
+		}
+		kind := KindTag
+		if match[2] == "@" {
+			kind = KindMention
+		}
+		counts[[2]string{kind, name}]++
+	}
+
+	tags := make([]model.WorkLogTag, 0, len(counts))
+	for key, occurrences := range counts {
+		tags = append(tags, model.WorkLogTag{Kind: key[0], Name: key[1], Occurrences: occurrences})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Kind != tags[j].Kind {
+			return tags[i].Kind < tags[j].Kind
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags
+}
+
+// SyncTags re-extracts the tags of a saved work log
+func SyncTags(workLog *model.WorkLog) error {
+	return work_log_tag_repo.ReplaceForWorkLog(workLog.ID, workLog.UserID, ParseTags(workLog.Content))
+}
+
+// ParseTagFilter turns a ?tag= value into a kind and name.
+// "@bob" is a mention; "#project-x" and "project-x" are tags.
+func ParseTagFilter(value string) (string, string) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case strings.HasPrefix(value, "@"):
+		return KindMention, strings.TrimPrefix(value, "@")
+	case strings.HasPrefix(value, "#"):
+		return KindTag, strings.TrimPrefix(value, "#")
+	default:
+		return KindTag, value
+	}
+}
+
+// ListTagCounts aggregates how often each tag was used by a user
+func ListTagCounts(userID int64, req *contract.ListWorkLogTagsRequest) ([]model.WorkLogTagCount, error) {
+	if req.Kind != "" && req.Kind != KindTag && req.Kind != KindMention {
+		return nil, errors.New("kind must be tag or mention")
+	}
+	if req.From != "" && !dateRegex.MatchString(req.From) {
+		return nil, errors.New("from must be in YYYY-MM-DD format")
+	}
+	if req.To != "" && !dateRegex.MatchString(req.To) {
+		return nil, errors.New("to must be in YYYY-MM-DD format")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultTagLimit
+	}
+	if limit > maxTagLimit {
+		limit = maxTagLimit
+	}
+
+	counts, err := work_log_tag_repo.CountByUserID(userID, req.Kind, req.From, req.To, limit)
+	if err != nil {
+		return nil, err
+	}
+	if counts == nil {
+		counts = []model.WorkLogTagCount{}
+	}
+	return counts, nil
+}
@@ -0,0 +1,230 @@
+package work_log_template_service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"worknote-api/contract"
+	"worknote-api/model"
+	"worknote-api/repos/work_log_repo"
+	"worknote-api/repos/work_log_template_repo"
+	"worknote-api/services/user_settings_service"
+	"worknote-api/services/work_log_item_service"
+	"worknote-api/services/work_log_service"
+	"worknote-api/utils/locale"
+	"worknote-api/utils/templatecheck"
+)
+
+// ErrWorkLogExists is returned when the day already has content
+var ErrWorkLogExists = work_log_service.ErrWorkLogExists
+
+// CreateTemplate creates a daily log template for a user
+func CreateTemplate(userID int64, req *contract.CreateWorkLogTemplateRequest) (*model.WorkLogTemplate, error) {
+	template := &model.WorkLogTemplate{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Content:   req.Content,
+		IsDefault: req.IsDefault,
+	}
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	// The first template becomes the default one
+	templates, err := work_log_template_repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		template.IsDefault = true
+	}
+	if err := validateName(templates, template); err != nil {
+		return nil, err
+	}
+
+	if err := work_log_template_repo.Create(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// ListTemplates retrieves the templates of a user
+func ListTemplates(userID int64) ([]model.WorkLogTemplate, error) {
+	return work_log_template_repo.ListByUserID(userID)
+}
+
+// GetTemplate retrieves a template of a user
+func GetTemplate(id, userID int64) (*model.WorkLogTemplate, error) {
+	return work_log_template_repo.GetByID(id, userID)
+}
+
+// UpdateTemplate updates a template of a user
+func UpdateTemplate(id, userID int64, req *contract.UpdateWorkLogTemplateRequest) (*model.WorkLogTemplate, error) {
+	template, err := work_log_template_repo.GetByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, nil // Not found
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		template.Name = name
+	}
+	if req.Content != nil {
+		template.Content = *req.Content
+	}
+	if req.IsDefault != nil {
+		template.IsDefault = *req.IsDefault
+	}
+	if err := validateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	templates, err := work_log_template_repo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateName(templates, template); err != nil {
+		return nil, err
+	}
+
+	if err := work_log_template_repo.Update(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteTemplate deletes a template of a user
+func DeleteTemplate(id, userID int64) error {
+	return work_log_template_repo.Delete(id, userID)
+}
+
+// PreviewTemplate renders a template for a date without saving anything
+func PreviewTemplate(id, userID int64, date string) (string, string, error) {
+	template, err := work_log_template_repo.GetByID(id, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if template == nil {
+		return "", "", errors.New("template not found")
+	}
+
+	date, err = resolveDate(userID, date)
+	if err != nil {
+		return "", "", err
+	}
+	content, err := Render(userID, template, date)
+	if err != nil {
+		return "", "", err
+	}
+	return date, content, nil
+}
+
+// CreateWorkLogFromTemplate creates the log of a day from a template, the default one when
+// templateID is 0. A day that already has content is left untouched.
+func CreateWorkLogFromTemplate(userID int64, date string, templateID int64) (*model.WorkLog, error) {
+	date, err := resolveDate(userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var template *model.WorkLogTemplate
+	if templateID != 0 {
+		template, err = work_log_template_repo.GetByID(templateID, userID)
+	} else {
+		template, err = work_log_template_repo.GetDefault(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, errors.New("template not found")
+	}
+
+	content, err := Render(userID, template, date)
+	if err != nil {
+		return nil, err
+	}
+	if content == "" {
+		return nil, errors.New("template renders to an empty work log")
+	}
+
+	return work_log_service.CreateWorkLog(userID, date, content)
+}
+
+// Render fills the placeholders of a template for a date (YYYY-MM-DD):
+// {{date}}, {{weekday}} in the user's locale, {{previous_date}} of the last logged day
+// before it, and {{open_items}} carried over from that day.
+func Render(userID int64, template *model.WorkLogTemplate, date string) (string, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", errors.New("date must be in YYYY-MM-DD format")
+	}
+	settings, err := user_settings_service.GetSettings(userID)
+	if err != nil {
+		return "", err
+	}
+
+	previousDate, openItems := "", ""
+	if strings.Contains(template.Content, "{{previous_date}}") || strings.Contains(template.Content, "{{open_items}}") {
+		previous, err := work_log_repo.GetLatestBefore(userID, date)
+		if err != nil {
+			return "", err
+		}
+		if previous != nil {
+			previousDate = previous.Date
+			if len(previousDate) > 10 {
+				previousDate = previousDate[:10]
+			}
+			openItems, err = renderOpenItems(previous.ID)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	content := strings.NewReplacer(
+		"{{date}}", date,
+		"{{weekday}}", locale.Weekday(day, settings.Locale),
+		"{{previous_date}}", previousDate,
+		"{{open_items}}", openItems,
+	).Replace(template.Content)
+
+	return strings.TrimSpace(content), nil
+}
+
+// renderOpenItems renders the items of a work log that are not done yet
+func renderOpenItems(workLogID int64) (string, error) {
+	items, err := work_log_item_service.ListItems(workLogID)
+	if err != nil {
+		return "", err
+	}
+	var open []model.WorkLogItem
+	for _, item := range items {
+		if item.Status != work_log_item_service.StatusDone {
+			open = append(open, item)
+		}
+	}
+	return work_log_item_service.RenderContent(open), nil
+}
+
+func resolveDate(userID int64, date string) (string, error) {
+	if date == "" {
+		date = "today"
+	}
+	return work_log_service.ResolveDate(userID, date)
+}
+
+func validateTemplate(template *model.WorkLogTemplate) error {
+	return templatecheck.Validate(template.Name, template.Content)
+}
+
+func validateName(templates []model.WorkLogTemplate, template *model.WorkLogTemplate) error {
+	names := make(map[int64]string, len(templates))
+	for _, other := range templates {
+		names[other.ID] = other.Name
+	}
+	return templatecheck.CheckUniqueName(template.ID, template.Name, names)
+}
@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// Line operations
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// maxCells bounds the table of the longest common subsequence, which takes one int
+// per pair of lines left once the common head and tail are set aside
+const maxCells = 1 << 21
+
+// ErrTooLarge is returned when the changed parts of two texts are too long to diff
+var ErrTooLarge = errors.New("too large to diff")
+
+// Line is one line of a diff
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines computes a line diff turning a into b, based on their longest common subsequence.
+// Returns ErrTooLarge when the lines that differ would need more than maxCells to compare.
+func Lines(a, b string) ([]Line, error) {
+	from := splitLines(a)
+	to := splitLines(b)
+
+	// Lines shared at both ends are equal in any longest common subsequence
+	head := 0
+	for head < len(from) && head < len(to) && from[head] == to[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(from)-head && tail < len(to)-head && from[len(from)-1-tail] == to[len(to)-1-tail] {
+		tail++
+	}
+
+	lines := make([]Line, 0, len(from)+len(to))
+	for _, text := range from[:head] {
+		lines = append(lines, Line{Op: OpEqual, Text: text})
+	}
+	middle, err := lcsLines(from[head:len(from)-tail], to[head:len(to)-tail])
+	if err != nil {
+		return nil, err
+	}
+	lines = append(lines, middle...)
+	for _, text := range from[len(from)-tail:] {
+		lines = append(lines, Line{Op: OpEqual, Text: text})
+	}
+	return lines, nil
+}
+
+func lcsLines(from, to []string) ([]Line, error) {
+	if (len(from)+1)*(len(to)+1) > maxCells {
+		return nil, ErrTooLarge
+	}
+
+	// lcs[i][j] is the LCS length of from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]Line, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, Line{Op: OpEqual, Text: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: OpDelete, Text: from[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: OpInsert, Text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, Line{Op: OpDelete, Text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, Line{Op: OpInsert, Text: to[j]})
+	}
+	return lines, nil
+}
+
+// Unified renders a diff with "+", "-" and " " line prefixes
+func Unified(lines []Line) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		switch line.Op {
+		case OpInsert:
+			sb.WriteString("+")
+		case OpDelete:
+			sb.WriteString("-")
+		default:
+			sb.WriteString(" ")
+		}
+		sb.WriteString(line.Text)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
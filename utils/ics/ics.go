@@ -0,0 +1,120 @@
+package ics
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is an all-day span of a calendar event. End is exclusive, as in iCalendar.
+type Event struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Days returns every date covered by the event, or false without expanding them when
+// the event covers more than max days
+func (e Event) Days(max int) ([]time.Time, bool) {
+	if e.End.After(e.Start.AddDate(0, 0, max)) {
+		return nil, false
+	}
+	var days []time.Time
+	for day := e.Start; day.Before(e.End); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days, true
+}
+
+// Parse reads the VEVENTs of an iCalendar (RFC 5545) document. Times are truncated to
+// their date, which is all a holiday calendar needs.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var current *Event
+	for _, line := range lines {
+		name, params, value := splitProperty(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &Event{}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current == nil {
+				continue
+			}
+			if current.Start.IsZero() {
+				return nil, errors.New("event without DTSTART")
+			}
+			if !current.End.After(current.Start) {
+				current.End = current.Start.AddDate(0, 0, 1)
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "SUMMARY":
+			current.Summary = unescape(value)
+		case name == "DTSTART", name == "DTEND":
+			date, err := parseDate(value)
+			if err != nil {
+				return nil, errors.New("invalid " + name + " " + value + " (" + params + ")")
+			}
+			if name == "DTSTART" {
+				current.Start = date
+			} else {
+				current.End = date
+			}
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, errors.New("no events found")
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines, which start with a space or a tab
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitProperty splits "NAME;PARAMS:VALUE"
+func splitProperty(line string) (name, params, value string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), "", ""
+	}
+	name, value = line[:colon], line[colon+1:]
+	if semi := strings.Index(name, ";"); semi >= 0 {
+		name, params = name[:semi], name[semi+1:]
+	}
+	return strings.ToUpper(name), params, value
+}
+
+// parseDate reads the date part of a DATE (20260101) or DATE-TIME (20260101T090000Z) value
+func parseDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, errors.New("too short")
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
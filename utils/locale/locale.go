@@ -0,0 +1,25 @@
+package locale
+
+import "time"
+
+// Indonesian day and month names, the time package only knows English ones
+var (
+	idWeekdays = []string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+	idMonths   = []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+)
+
+// Weekday returns the full weekday name of a date, e.g. "Friday"
+func Weekday(date time.Time, locale string) string {
+	if locale == "id" {
+		return idWeekdays[date.Weekday()]
+	}
+	return date.Weekday().String()
+}
+
+// FormatDate formats a date as "Fri, 21 November 2025"
+func FormatDate(date time.Time, locale string) string {
+	if locale == "id" {
+		return idWeekdays[date.Weekday()][:3] + ", " + date.Format("2") + " " + idMonths[date.Month()-1] + " " + date.Format("2006")
+	}
+	return date.Format("Mon, 2 January 2006")
+}
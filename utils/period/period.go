@@ -0,0 +1,183 @@
+package period
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Period types
+const (
+	Week    = "week"
+	Month   = "month"
+	Quarter = "quarter"
+	Year    = "year"
+	Custom  = "custom"
+)
+
+// MaxCustomDays bounds a custom date range
+const MaxCustomDays = 366
+
+var (
+	weekRegex    = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+	monthRegex   = regexp.MustCompile(`^(\d{4})-(0[1-9]|1[0-2])$`)
+	quarterRegex = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+	yearRegex    = regexp.MustCompile(`^(\d{4})$`)
+	customRegex  = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\.\.(\d{4}-\d{2}-\d{2})$`)
+)
+
+// Period is an inclusive range of dates a summary covers
+type Period struct {
+	Type  string
+	Start time.Time
+	End   time.Time
+}
+
+// IsValidType checks whether a period type is known
+func IsValidType(periodType string) bool {
+	switch periodType {
+	case Week, Month, Quarter, Year, Custom:
+		return true
+	}
+	return false
+}
+
+// StartDate returns the first day as YYYY-MM-DD
+func (p Period) StartDate() string {
+	return p.Start.Format("2006-01-02")
+}
+
+// EndDate returns the last day as YYYY-MM-DD
+func (p Period) EndDate() string {
+	return p.End.Format("2006-01-02")
+}
+
+// Label names the period: 2026-W42, 2026-10, 2026-Q4, 2026 or 2026-10-01..2026-10-15
+func (p Period) Label() string {
+	switch p.Type {
+	case Week:
+		year, week := p.Start.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case Month:
+		return p.Start.Format("2006-01")
+	case Quarter:
+		return fmt.Sprintf("%04d-Q%d", p.Start.Year(), (int(p.Start.Month())-1)/3+1)
+	case Year:
+		return p.Start.Format("2006")
+	}
+	return p.StartDate() + ".." + p.EndDate()
+}
+
+// Describe names the period for a sentence, e.g. "the month 2026-10 (2026-10-01 to 2026-10-31)"
+func (p Period) Describe() string {
+	if p.Type == Custom {
+		return fmt.Sprintf("the period from %s to %s", p.StartDate(), p.EndDate())
+	}
+	return fmt.Sprintf("the %s %s (%s to %s)", p.Type, p.Label(), p.StartDate(), p.EndDate())
+}
+
+// Parse reads a period label and detects its type
+func Parse(label string) (Period, error) {
+	if m := weekRegex.FindStringSubmatch(label); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		return ISOWeek(year, week)
+	}
+	if m := monthRegex.FindStringSubmatch(label); m != nil {
+		start, _ := time.Parse("2006-01", label)
+		return Period{Type: Month, Start: start, End: start.AddDate(0, 1, -1)}, nil
+	}
+	if m := quarterRegex.FindStringSubmatch(label); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return Period{Type: Quarter, Start: start, End: start.AddDate(0, 3, -1)}, nil
+	}
+	if m := yearRegex.FindStringSubmatch(label); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return Period{Type: Year, Start: start, End: start.AddDate(1, 0, -1)}, nil
+	}
+	if m := customRegex.FindStringSubmatch(label); m != nil {
+		return NewCustom(m[1], m[2])
+	}
+	return Period{}, errors.New("invalid period, expected YYYY-Www, YYYY-MM, YYYY-Qn, YYYY or YYYY-MM-DD..YYYY-MM-DD")
+}
+
+// ParseType reads a period label that must be of the given type
+func ParseType(periodType, label string) (Period, error) {
+	p, err := Parse(label)
+	if err != nil {
+		return Period{}, err
+	}
+	if p.Type != periodType {
+		return Period{}, fmt.Errorf("period %s is not a %s", label, periodType)
+	}
+	return p, nil
+}
+
+// ISOWeek returns the Monday to Sunday range of an ISO week
+func ISOWeek(year, week int) (Period, error) {
+	// Week 1 is the week holding January 4th
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	start := monday.AddDate(0, 0, (week-1)*7)
+	if y, w := start.ISOWeek(); week < 1 || y != year || w != week {
+		return Period{}, fmt.Errorf("week %d does not exist in %d", week, year)
+	}
+	return Period{Type: Week, Start: start, End: start.AddDate(0, 0, 6)}, nil
+}
+
+// NewCustom builds a custom period from two inclusive dates (YYYY-MM-DD)
+func NewCustom(startDate, endDate string) (Period, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return Period{}, errors.New("start must be in YYYY-MM-DD format")
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return Period{}, errors.New("end must be in YYYY-MM-DD format")
+	}
+	if end.Before(start) {
+		return Period{}, errors.New("start must be before or equal to end")
+	}
+	if end.Sub(start) >= MaxCustomDays*24*time.Hour {
+		return Period{}, fmt.Errorf("a custom period must not exceed %d days", MaxCustomDays)
+	}
+	return Period{Type: Custom, Start: start, End: end}, nil
+}
+
+// Containing returns the week, month, quarter or year a date falls in
+func Containing(date time.Time, periodType string) (Period, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch periodType {
+	case Week:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return Period{Type: Week, Start: start, End: start.AddDate(0, 0, 6)}, nil
+	case Month:
+		start := day.AddDate(0, 0, 1-day.Day())
+		return Period{Type: Month, Start: start, End: start.AddDate(0, 1, -1)}, nil
+	case Quarter:
+		start := time.Date(day.Year(), time.Month((int(day.Month())-1)/3*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return Period{Type: Quarter, Start: start, End: start.AddDate(0, 3, -1)}, nil
+	case Year:
+		start := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return Period{Type: Year, Start: start, End: start.AddDate(1, 0, -1)}, nil
+	}
+	return Period{}, fmt.Errorf("no current period for type %q", periodType)
+}
+
+// FromDates rebuilds a stored period from its type and dates
+func FromDates(periodType, startDate, endDate string) (Period, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return Period{}, err
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return Period{}, err
+	}
+	return Period{Type: periodType, Start: start, End: end}, nil
+}
@@ -0,0 +1,40 @@
+package templatecheck
+
+import (
+	"errors"
+	"strings"
+)
+
+// Limits shared by every kind of user-defined template
+const (
+	MaxNameLength    = 100
+	MaxContentLength = 10000
+)
+
+// Validate checks the name and content every template needs
+func Validate(name, content string) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > MaxNameLength {
+		return errors.New("name is too long")
+	}
+	if strings.TrimSpace(content) == "" {
+		return errors.New("content is required")
+	}
+	if len(content) > MaxContentLength {
+		return errors.New("content is too long")
+	}
+	return nil
+}
+
+// CheckUniqueName rejects a name that another template of the user has, ignoring case.
+// names maps the ID of each template of the user to its name.
+func CheckUniqueName(id int64, name string, names map[int64]string) error {
+	for otherID, other := range names {
+		if otherID != id && strings.EqualFold(other, name) {
+			return errors.New("a template with this name already exists")
+		}
+	}
+	return nil
+}